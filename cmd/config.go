@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/fa7ad/esq/internal/config"
+	"github.com/fa7ad/esq/internal/exitcode"
+	"github.com/fa7ad/esq/internal/output"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the contexts of the config file.",
+	Long: fmt.Sprintf(`Manage the contexts of the config file.
+
+A context is a named set of settings in the contexts map of the config file, such as the node,
+credentials, default index, time field and output format of a cluster. The settings of the
+current context, or of the context given with --context, take precedence over the top-level
+settings of the config file, but not over environment variables and flags.
+
+Example config file:
+	current-context: local
+	contexts:
+	  local:
+	    node: http://localhost:9200
+	  prod:
+	    node: https://es.example.com
+	    api-key: your_base64_api_key
+	    index: 'logs-*'
+	    time-field: '@timestamp'
+
+Examples:
+	# List the contexts
+	%[1]s config get-contexts
+
+	# Switch to the prod context
+	%[1]s config use-context prod
+
+	# Query staging once, without switching
+	%[1]s --context staging --kql "level:error"
+`, AppName),
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return exitcode.Wrap(exitcode.Usage, readConfig(cfgFile, AppName))
+	},
+}
+
+var getContextsCmd = &cobra.Command{
+	Use:   "get-contexts",
+	Short: "List the contexts of the config file.",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, err := contexts()
+		if err != nil {
+			return exitcode.Wrap(exitcode.Usage, err)
+		}
+		names := make([]string, 0, len(all))
+		for name := range all {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		current := currentContext()
+		rows := make([]any, 0, len(names))
+		for _, name := range names {
+			marker := ""
+			if name == current {
+				marker = "*"
+			}
+			rows = append(rows, map[string]any{
+				"current": marker,
+				"name":    name,
+				"node":    all[name]["node"],
+				"index":   all[name]["index"],
+			})
+		}
+
+		columns := []string{"current", "name", "node", "index"}
+		return output.NewTableFormatter(columns, 0, false, ",").Format(os.Stdout, rows)
+	},
+}
+
+var currentContextCmd = &cobra.Command{
+	Use:   "current-context",
+	Short: "Print the name of the current context.",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := currentContext()
+		if name == "" {
+			return exitcode.Wrap(exitcode.Usage, fmt.Errorf("no current context is set"))
+		}
+		fmt.Println(name)
+		return nil
+	},
+}
+
+var useContextCmd = &cobra.Command{
+	Use:   "use-context NAME",
+	Short: "Set the current context in the config file.",
+	Args:  usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		all, err := contexts()
+		if err != nil {
+			return exitcode.Wrap(exitcode.Usage, err)
+		}
+		if _, ok := all[name]; !ok {
+			return exitcode.Wrap(exitcode.Usage, fmt.Errorf("context '%s' not found in config file", name))
+		}
+		if err := config.SetCurrentContext(viper.ConfigFileUsed(), name); err != nil {
+			return err
+		}
+		fmt.Printf("Switched to context %q.\n", name)
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(getContextsCmd, currentContextCmd, useContextCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fa7ad/esq/internal/esclient"
+	"github.com/fa7ad/esq/internal/exitcode"
+	"github.com/fa7ad/esq/internal/options"
+	"github.com/fa7ad/esq/internal/validation"
+)
+
+const (
+	DefaultSlices = 4
+	// progressInterval is how often export progress is reported on stderr.
+	progressInterval = 5 * time.Second
+)
+
+var exportOpts options.ExportOptions
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export all matching documents as NDJSON using parallel slices.",
+	Long: fmt.Sprintf(`Export every document matching the query as newline-delimited JSON, one _source
+(or full hit with --full-hit) per line.
+
+The export opens a point in time on the index and splits it into --slices slices, which are
+fetched concurrently by up to --workers workers. The slices are merged into a single output,
+or written to one file per slice with --per-slice. Use --size to set the page size of each slice.
+Without a query, the whole index is exported. Progress is reported on stderr.
+
+Examples:
+	# Export a whole index into a single file
+	%[1]s export -n http://localhost:9200 -i orders --slices 8 -s 5000 --output-file orders.ndjson
+
+	# Export a day of audit events, one file per slice (audit-0.ndjson, audit-1.ndjson, ...)
+	%[1]s export -n http://localhost:9200 -i audit-logs --from now-1d --per-slice --output-file audit.ndjson
+`, AppName),
+	Args: usageArgs(cobra.NoArgs),
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := initConfigMatchAll(cmd); err != nil {
+			return exitcode.Wrap(exitcode.Usage, err)
+		}
+		if cliArgs.DryRun {
+			// a dry run writes no output files
+			exportOpts.PerSlice = false
+		}
+		return exitcode.Wrap(exitcode.Usage, validation.ValidateExportOptions(exportOpts, cliArgs.OutputOptions))
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		esClient, err := esclient.NewElasticsearchClient(cliArgs.AuthOptions, cliArgs.ElasticOptions)
+		if err != nil {
+			return fmt.Errorf("failed to create ES client: %w", err)
+		}
+
+		if cliArgs.NeedsTimeField() {
+			if err := esClient.ResolveTimeField(cmd.Context(), &cliArgs.ElasticOptions); err != nil {
+				return err
+			}
+		}
+
+		writers, err := openSliceWriters()
+		if err != nil {
+			return err
+		}
+
+		progress := newExportProgress(exportOpts.Slices)
+		stopProgress := progress.report(progressInterval)
+
+		// a merged output is shared by all slices, per-slice outputs are not
+		var mu sync.Mutex
+		err = esClient.SearchSliced(cmd.Context(), cliArgs.ElasticOptions, exportOpts.Slices, exportOpts.Workers,
+			func(slice int, page map[string]any) error {
+				rw := writers[0]
+				if exportOpts.PerSlice {
+					rw = writers[slice]
+				} else {
+					mu.Lock()
+					defer mu.Unlock()
+				}
+				if err := rw.WriteHits(page); err != nil {
+					return err
+				}
+				progress.add(slice, page)
+				return nil
+			})
+		stopProgress()
+		closeErr := closeWriters(writers)
+		if err != nil {
+			return fmt.Errorf("failed to export: %w", err)
+		}
+		return closeErr
+	},
+}
+
+// closeWriters closes the outputs, and returns the first error.
+func closeWriters(writers []*options.ResultWriter) error {
+	var first error
+	for _, rw := range writers {
+		if err := rw.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// openSliceWriters opens the merged output, or one output per slice.
+func openSliceWriters() ([]*options.ResultWriter, error) {
+	if !exportOpts.PerSlice {
+		rw, err := cliArgs.NewResultWriter()
+		if err != nil {
+			return nil, err
+		}
+		return []*options.ResultWriter{rw}, nil
+	}
+
+	writers := make([]*options.ResultWriter, 0, exportOpts.Slices)
+	for i := range exportOpts.Slices {
+		sliceOutput := cliArgs.OutputOptions
+		sliceOutput.OutputFile = exportOpts.SliceFile(cliArgs.OutputFile, i)
+		rw, err := sliceOutput.NewResultWriter()
+		if err != nil {
+			closeWriters(writers)
+			return nil, err
+		}
+		writers = append(writers, rw)
+	}
+	return writers, nil
+}
+
+// exportProgress counts the documents exported by each slice.
+type exportProgress struct {
+	counts []atomic.Int64
+	start  time.Time
+}
+
+func newExportProgress(slices int) *exportProgress {
+	return &exportProgress{counts: make([]atomic.Int64, slices), start: time.Now()}
+}
+
+func (p *exportProgress) add(slice int, page map[string]any) {
+	hits, _ := page["hits"].([]any)
+	p.counts[slice].Add(int64(len(hits)))
+}
+
+// print writes the total and per-slice document counts to stderr.
+func (p *exportProgress) print(prefix string) {
+	var total int64
+	perSlice := make([]string, len(p.counts))
+	for i := range p.counts {
+		n := p.counts[i].Load()
+		total += n
+		perSlice[i] = fmt.Sprintf("%d/%d: %d", i, len(p.counts), n)
+	}
+	fmt.Fprintf(os.Stderr, "%s %d documents in %s [slice %s]\n",
+		prefix, total, time.Since(p.start).Round(time.Second), strings.Join(perSlice, ", slice "))
+}
+
+// report prints progress every interval until the returned function is
+// called, which prints the final counts.
+func (p *exportProgress) report(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.print("exported")
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+		p.print("finished exporting")
+	}
+}
+
+func init() {
+	exportCmd.Flags().IntVar(&exportOpts.Slices, "slices", DefaultSlices, "Number of slices to split the export into.")
+	exportCmd.Flags().IntVar(&exportOpts.Workers, "workers", runtime.NumCPU(), "Maximum number of slices fetched concurrently.")
+	exportCmd.Flags().BoolVar(&exportOpts.PerSlice, "per-slice", false, "Write one file per slice, named after --output-file (e.g. out-0.ndjson).")
+
+	rootCmd.AddCommand(exportCmd)
+}
@@ -1,22 +1,32 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
+	"github.com/fa7ad/esq/internal/config"
 	"github.com/fa7ad/esq/internal/esclient"
+	"github.com/fa7ad/esq/internal/exitcode"
 	"github.com/fa7ad/esq/internal/options"
+	"github.com/fa7ad/esq/internal/output"
 	"github.com/fa7ad/esq/internal/validation"
 )
 
 const (
-	DefaultSize = 100
-	AppName     = "esq"
+	DefaultSize         = 100
+	DefaultMaxRetries   = 3
+	DefaultRetryBackoff = 500 * time.Millisecond
+	AppName             = "esq"
 )
 
 var cfgFile string
@@ -27,8 +37,9 @@ var rootCmd = &cobra.Command{
 	Short: "A CLI tool to query Elasticsearch.",
 	Long: fmt.Sprintf(`%[1]s - A CLI tool to query Elasticsearch.
 
-Pass a query in KQL, Lucene, or Elasticsearch Query DSL (as argument or a file) to search across your Elasticsearch indices.
-It supports output in JSON or text format, and allows you to apply jq expressions to the results
+Pass a query in KQL, Lucene, or Elasticsearch Query DSL (as argument or a file) to search across your Elasticsearch indices,
+or run an ES|QL query.
+It supports output as an aligned table, JSON, NDJSON, CSV, TSV or text, and allows you to apply jq expressions to the results
 
 You can configure %[1]s using command-line flags, environment variables (prefixed with ESQ_),
 or a configuration file (e.g., $HOME/.esq.yaml).
@@ -49,14 +60,40 @@ Examples:
 	# Use a custom config file
 	%[1]s --config /etc/%[1]s/config.yaml -i my-index --kql "error"
 
+	# Fetch every matching document, page by page
+	%[1]s -n http://localhost:9200 -i audit-logs --kql "event.action:login" --from now-1d --all -o json
+
+	# Stream one _source per line into other tools
+	%[1]s -n http://localhost:9200 -i my-logs --kql "level:error" --all -o ndjson | jq -c .message
+
+	# Export selected fields as CSV for spreadsheets
+	%[1]s -n http://localhost:9200 -i orders --kql "status:paid" -o csv --fields _id,customer.name,total --output-file orders.csv
+
+	# Render each hit as a log line through a Go template
+	%[1]s -n http://localhost:9200 -i my-logs --kql "service:api" --template '{{.timestamp | date "15:04:05"}} {{.level | color "red"}} {{.message}}'
+
+	# Count errors per service and hour, without fetching hits
+	%[1]s -n http://localhost:9200 -i my-logs --kql "level:error" --from now-1d --terms service:20 --date-histogram @timestamp:1h
+
+	# Run an ES|QL query over the last hour
+	%[1]s -n http://localhost:9200 --esql "FROM my-logs | WHERE level == ? | STATS count() BY service" --esql-params error --from now-1h --time-field @timestamp
+
+	# Find processes that made a network connection, shown as grouped sequences
+	%[1]s -n http://localhost:9200 -i logs-endpoint-* --eql "sequence by host.id with maxspan=1m [process where process.name == \"curl\"] [network where true]" --from now-1d -o table
+
+	# Count errors per service with SQL, or show the search it translates to
+	%[1]s -n http://localhost:9200 --sql "SELECT service, COUNT(*) AS errors FROM \"my-logs\" WHERE level = 'error' GROUP BY service"
+	%[1]s -n http://localhost:9200 --sql "SELECT message FROM \"my-logs\" WHERE level = 'error'" --sql-translate
+
 	# Apply a jq expression to output
 	%[1]s -n http://localhost:9200 -i my-logs --kql "foo:bar" -o json --jq ".hits.hits | map({id: ._id, source: ._source})"
 `, AppName),
 	Version:       "0.1.0",
+	Args:          usageArgs(cobra.NoArgs),
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		return InitConfig(cfgFile, AppName, &cliArgs)
+		return exitcode.Wrap(exitcode.Usage, InitConfig(cmd, cfgFile, AppName, &cliArgs))
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		esClient, err := esclient.NewElasticsearchClient(cliArgs.AuthOptions, cliArgs.ElasticOptions)
@@ -64,29 +101,183 @@ Examples:
 			return fmt.Errorf("failed to create ES client: %w", err)
 		}
 
-		results, err := esClient.Search(cliArgs.ElasticOptions)
+		// the events of an EQL query are ordered and filtered by their timestamp field
+		if cliArgs.EQL != "" && cliArgs.TimeField == "" {
+			cliArgs.TimeField = cliArgs.TimestampField
+		}
+
+		// an ES|QL query may not name an --index to detect the time field of
+		if cliArgs.NeedsTimeField() && cliArgs.Index != "" {
+			if err := esClient.ResolveTimeField(cmd.Context(), &cliArgs.ElasticOptions); err != nil {
+				return err
+			}
+		}
+
+		if cliArgs.ESQL != "" {
+			results, err := esClient.ESQL(cmd.Context(), cliArgs.ElasticOptions)
+			if err != nil {
+				return fmt.Errorf("failed to execute ES|QL query: %w", err)
+			}
+			return writeResults(results)
+		}
+
+		if cliArgs.SQLTranslate {
+			results, err := esClient.SQLTranslate(cmd.Context(), cliArgs.ElasticOptions)
+			if err != nil {
+				return fmt.Errorf("failed to translate SQL query: %w", err)
+			}
+			return cliArgs.OutputResults(results)
+		}
+
+		if cliArgs.SQL != "" {
+			return writePages(func(handle esclient.PageHandler) error {
+				if err := esClient.SQL(cmd.Context(), cliArgs.ElasticOptions, handle); err != nil {
+					return fmt.Errorf("failed to execute SQL query: %w", err)
+				}
+				return nil
+			})
+		}
+
+		if cliArgs.EQL != "" {
+			results, err := esClient.EQL(cmd.Context(), cliArgs.ElasticOptions)
+			if err != nil {
+				return fmt.Errorf("failed to execute EQL query: %w", err)
+			}
+			return writeResults(results)
+		}
+
+		if cliArgs.Count {
+			results, err := esClient.Count(cmd.Context(), cliArgs.ElasticOptions)
+			if err != nil {
+				return fmt.Errorf("failed to count documents: %w", err)
+			}
+			if err := cliArgs.OutputResults(results); err != nil {
+				return err
+			}
+			count, _ := results["count"].(float64)
+			return resultError(false, int(count))
+		}
+
+		// aggregation shortcuts only fetch hits if asked to
+		if cliArgs.HasAggregations() && !cmd.Flags().Changed("size") {
+			cliArgs.Size = 0
+		}
+
+		if cliArgs.Paginate() {
+			return writePages(func(handle esclient.PageHandler) error {
+				if err := esClient.SearchAll(cmd.Context(), cliArgs.ElasticOptions, handle); err != nil {
+					return fmt.Errorf("failed to execute search: %w", err)
+				}
+				return nil
+			})
+		}
+
+		results, err := esClient.Search(cmd.Context(), cliArgs.ElasticOptions)
 		if err != nil {
 			return fmt.Errorf("failed to execute search: %w", err)
 		}
+		return writeResults(results)
+	},
+}
 
-		err = cliArgs.OutputResults(results)
-		if err != nil {
-			return err
+// writeResults writes the results of a single request and their summary, and
+// returns the error setting the exit code.
+func writeResults(results map[string]any) error {
+	if err := cliArgs.OutputResults(results); err != nil {
+		return err
+	}
+
+	hits, _ := results["hits"].([]any)
+	printSummary(results, len(hits))
+	return resultError(esclient.IsPartial(results), hitCount(results, len(hits)))
+}
+
+// writePages writes the pages that fetch passes to its handler as they come,
+// then their summary like that of a single response, and returns the error
+// setting the exit code.
+func writePages(fetch func(handle esclient.PageHandler) error) error {
+	rw, err := cliArgs.NewResultWriter()
+	if err != nil {
+		return err
+	}
+
+	summary := map[string]any{}
+	shown := 0
+	partial := false
+	err = fetch(func(page map[string]any) error {
+		if total, ok := page["total"]; ok {
+			summary["total"] = total
+		}
+		// SQL responses do not report their took
+		if took, ok := page["took"].(float64); ok {
+			sum, _ := summary["took"].(float64)
+			summary["took"] = sum + took
 		}
+		hits, _ := page["hits"].([]any)
+		shown += len(hits)
+		partial = partial || esclient.IsPartial(page)
+		return rw.WritePage(page)
+	})
+	if err == nil {
+		err = rw.Finish(summary, shown)
+	}
+	if closeErr := rw.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	printSummary(summary, shown)
+	return resultError(partial, hitCount(summary, shown))
+}
 
-		return nil
-	},
+// hitCount returns the total hit count of a search response if tracked, the
+// number of hits shown otherwise.
+func hitCount(response map[string]any, shown int) int {
+	if total, ok := response["total"].(map[string]any); ok {
+		if value, ok := total["value"].(float64); ok {
+			return int(value)
+		}
+	}
+	return shown
+}
+
+// resultError returns the error setting the exit code of a successful search:
+// partial results first, then no hits with --fail-on-empty.
+func resultError(partial bool, hits int) error {
+	switch {
+	case partial:
+		return exitcode.ErrPartialResults
+	case hits == 0 && cliArgs.FailOnEmpty:
+		return exitcode.ErrNoHits
+	}
+	return nil
+}
+
+// printSummary writes the number of hits shown out of the total to stderr,
+// unless the table output already ends with it.
+func printSummary(response map[string]any, shown int) {
+	if cliArgs.Output == "table" {
+		return
+	}
+	fmt.Fprintln(os.Stderr, output.Summary(response, shown))
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil && !errors.Is(err, esclient.ErrDryRun) {
+		if !exitcode.Quiet(err) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(int(exitcode.FromError(err)))
 	}
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", fmt.Sprintf("config file (default is $HOME/.%s.yaml)", AppName))
+	rootCmd.PersistentFlags().String("context", "", "Name of the config file context to use (default: the current-context of the config file).")
 
 	rootCmd.PersistentFlags().StringVar(&cliArgs.KQL, "kql", "", "Kibana Query Language (KQL) query string.")
 	rootCmd.PersistentFlags().StringVar(&cliArgs.DSL, "dsl", "", "Elasticsearch Query DSL JSON string. Must be valid JSON string.")
@@ -94,21 +285,81 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&cliArgs.QueryFile, "query-file", "f", "", "Path to a file containing the Elasticsearch Query DSL (JSON) to use.")
 	rootCmd.PersistentFlags().StringVar(&cliArgs.From, "from", "", "Start time (ISO8601 or ES-relative like 'now-1d')")
 	rootCmd.PersistentFlags().StringVar(&cliArgs.To, "to", "", "End time (ISO8601 or ES-relative like 'now')")
+	rootCmd.PersistentFlags().StringVar(&cliArgs.TimeField, "time-field", "", "Date field --from/--to apply to (default: detected from the index mapping, e.g. @timestamp)")
 
-	rootCmd.PersistentFlags().StringVarP(&cliArgs.Node, "node", "n", "", "Elasticsearch node URL (e.g., http://localhost:9200)")
+	rootCmd.PersistentFlags().StringSliceVarP(&cliArgs.Nodes, "node", "n", nil, "Elasticsearch node URLs, comma-separated or repeated (e.g., http://localhost:9200)")
+	rootCmd.PersistentFlags().StringVar(&cliArgs.CloudID, "cloud-id", "", "Cloud ID of an Elastic Cloud deployment to connect to, instead of --node.")
 	rootCmd.PersistentFlags().StringVarP(&cliArgs.Index, "index", "i", "", "Elasticsearch index pattern (e.g., a2x-prod1*)")
-	rootCmd.PersistentFlags().IntVarP(&cliArgs.Size, "size", "s", DefaultSize, fmt.Sprintf("Number of results to return (default: %d).", DefaultSize))
+	rootCmd.PersistentFlags().StringVar(&cliArgs.CACert, "ca-cert", "", "Path to a PEM file of the certificate authorities to trust instead of the system ones.")
+	rootCmd.PersistentFlags().StringVar(&cliArgs.ClientCert, "client-cert", "", "Path to a PEM client certificate for mutual TLS (requires --client-key).")
+	rootCmd.PersistentFlags().StringVar(&cliArgs.ClientKey, "client-key", "", "Path to the PEM private key of --client-cert.")
+	rootCmd.PersistentFlags().StringVar(&cliArgs.CAFingerprint, "ca-fingerprint", "", "SHA-256 fingerprint (hex) of a certificate the node must present, e.g. the one printed by Elasticsearch on first launch.")
+	rootCmd.PersistentFlags().BoolVar(&cliArgs.InsecureSkipVerify, "insecure-skip-verify", false, "Skip the verification of the node's TLS certificate. Insecure, for testing only.")
+	rootCmd.PersistentFlags().BoolVar(&cliArgs.Sniff, "sniff", false, "Discover the nodes of the cluster on start, and use them all.")
+	rootCmd.PersistentFlags().DurationVar(&cliArgs.SniffInterval, "sniff-interval", 0, "Discover the nodes of the cluster again at this interval with --sniff (e.g. 5m).")
+	rootCmd.PersistentFlags().IntVar(&cliArgs.MaxRetries, "max-retries", DefaultMaxRetries, "Maximum number of retries of a request, 0 disabling retries.")
+	rootCmd.PersistentFlags().IntSliceVar(&cliArgs.RetryOnStatus, "retry-on-status", options.DefaultRetryOnStatus, "Response statuses of a request to retry, besides connection errors.")
+	rootCmd.PersistentFlags().DurationVar(&cliArgs.RetryBackoff, "retry-backoff", DefaultRetryBackoff, "Wait before the first retry, doubled on each further retry (max 30s).")
+	rootCmd.PersistentFlags().DurationVar(&cliArgs.Timeout, "timeout", 0, "Timeout of each request attempt (e.g. 30s), 0 meaning no timeout.")
+	rootCmd.PersistentFlags().IntVarP(&cliArgs.Size, "size", "s", DefaultSize, fmt.Sprintf("Number of results to return, or the page size with --all/--limit (default: %d).", DefaultSize))
+	rootCmd.PersistentFlags().StringArrayVar(&cliArgs.Filter, "filter", nil, "Only match documents whose field matches a value, as field=value (repeatable).")
+	rootCmd.PersistentFlags().StringArrayVar(&cliArgs.Exclude, "exclude", nil, "Exclude documents whose field matches a value, as field=value (repeatable).")
+	rootCmd.PersistentFlags().StringSliceVar(&cliArgs.Exists, "exists", nil, "Only match documents with a value for these fields.")
+	rootCmd.PersistentFlags().StringSliceVar(&cliArgs.Missing, "missing", nil, "Only match documents without a value for these fields.")
+	rootCmd.PersistentFlags().StringArrayVar(&cliArgs.Range, "range", nil, "Only match documents whose field is in a range, as field>=value with >=, <=, > or < (repeatable).")
+	rootCmd.PersistentFlags().StringSliceVar(&cliArgs.SourceIncludes, "source-includes", nil, "Only return these _source fields of each hit, as wildcard patterns (e.g. message,host.*).")
+	rootCmd.PersistentFlags().StringSliceVar(&cliArgs.SourceExcludes, "source-excludes", nil, "Omit these _source fields of each hit, as wildcard patterns ('*' omits the _source).")
+	rootCmd.PersistentFlags().StringSliceVar(&cliArgs.DocvalueFields, "docvalue-fields", nil, "Also return these fields of each hit from doc values.")
+	rootCmd.PersistentFlags().StringSliceVar(&cliArgs.Sort, "sort", nil, "Sort hits by fields, as field[:asc|desc] (e.g. @timestamp:desc,host.name).")
+	rootCmd.PersistentFlags().BoolVar(&cliArgs.All, "all", false, "Fetch all matching documents, paging with a point in time and search_after.")
+	rootCmd.PersistentFlags().IntVar(&cliArgs.Limit, "limit", 0, "Fetch up to this many matching documents, paging like --all.")
+
+	rootCmd.Flags().StringVar(&cliArgs.ESQL, "esql", "", "ES|QL query to run with the _query API instead of a search (e.g. 'FROM logs-* | STATS count() BY host.name').")
+	rootCmd.Flags().StringArrayVar(&cliArgs.ESQLParams, "esql-params", nil, "Positional parameter of the ES|QL query, for its ? placeholders (repeatable); numbers, booleans and null are typed, quote them as JSON strings otherwise.")
+	rootCmd.Flags().StringVar(&cliArgs.EQL, "eql", "", "EQL query to run with the _eql/search API instead of a search (e.g. 'process where process.name == \"curl\"').")
+	rootCmd.Flags().StringVar(&cliArgs.TimestampField, "timestamp-field", "", "Timestamp field of the EQL events (default: --time-field, or @timestamp).")
+	rootCmd.Flags().StringVar(&cliArgs.EventCategoryField, "event-category-field", "", "Event category field of the EQL events (default: event.category).")
+	rootCmd.Flags().StringVar(&cliArgs.TiebreakerField, "tiebreaker-field", "", "Field to order EQL events with the same timestamp.")
+	rootCmd.Flags().StringVar(&cliArgs.SQL, "sql", "", "Elasticsearch SQL query to run with the _sql API instead of a search, fetching --size rows per page (e.g. 'SELECT host.name, COUNT(*) FROM \"logs-*\" GROUP BY host.name').")
+	rootCmd.Flags().BoolVar(&cliArgs.SQLTranslate, "sql-translate", false, "Print the search the --sql query translates to as JSON, instead of running it.")
+	rootCmd.Flags().BoolVar(&cliArgs.FailOnEmpty, "fail-on-empty", false, "Exit with status 1 if no documents matched, like grep.")
+	rootCmd.Flags().BoolVar(&cliArgs.Count, "count", false, "Only count the matching documents, with the _count API.")
+
+	rootCmd.Flags().StringSliceVar(&cliArgs.Terms, "terms", nil, "Aggregate the top values of a field, as field[:size] (default size 10).")
+	rootCmd.Flags().StringSliceVar(&cliArgs.DateHistogram, "date-histogram", nil, "Aggregate documents over time, as field:interval (e.g. @timestamp:1h).")
+	rootCmd.Flags().StringSliceVar(&cliArgs.Stats, "stats", nil, "Aggregate the count, min, max, avg and sum of a numeric field.")
+	rootCmd.Flags().StringSliceVar(&cliArgs.Cardinality, "cardinality", nil, "Aggregate the approximate number of distinct values of a field.")
+	rootCmd.Flags().StringSliceVar(&cliArgs.Percentiles, "percentiles", nil, "Aggregate the percentiles of a numeric field.")
 
 	rootCmd.PersistentFlags().StringVar(&cliArgs.APIKey, "api-key", "", "Elasticsearch API Key for authentication (base64 encoded string or id:api_key object).")
 	rootCmd.PersistentFlags().StringVar(&cliArgs.Username, "username", "", "Username for basic authentication.")
 	rootCmd.PersistentFlags().StringVar(&cliArgs.Password, "password", "", "Password for basic authentication.")
+	rootCmd.PersistentFlags().BoolVar(&cliArgs.PasswordStdin, "password-stdin", false, "Read the password of --username from stdin.")
+	rootCmd.PersistentFlags().StringVar(&cliArgs.PasswordFile, "password-file", "", "Path to a file containing the password of --username.")
+	rootCmd.PersistentFlags().StringVar(&cliArgs.APIKeyFile, "api-key-file", "", "Path to a file containing the API key.")
+	rootCmd.PersistentFlags().StringVar(&cliArgs.CredentialCommand, "credential-command", "", "Shell command printing the password of --username, or else the API key (e.g. 'pass show es/prod').")
+	rootCmd.PersistentFlags().StringVar(&cliArgs.BearerToken, "bearer-token", "", "OAuth2/OIDC access token for bearer authentication.")
+	rootCmd.PersistentFlags().StringVar(&cliArgs.ServiceToken, "service-token", "", "Elasticsearch service account token for authentication.")
 
-	rootCmd.PersistentFlags().StringVarP(&cliArgs.Output, "output", "o", "text", "Output format (choices: json, text)")
+	rootCmd.PersistentFlags().CountVarP(&cliArgs.Verbose, "verbose", "v", "Log each request to stderr with its status, latency and response size; repeat (-vv) to log headers and bodies.")
+	rootCmd.PersistentFlags().Bool("debug", false, "Log each request to stderr with its headers and bodies, like -vv.")
+	rootCmd.PersistentFlags().BoolVar(&cliArgs.DryRun, "dry-run", false, "Print the requests as JSON instead of sending them, with secrets redacted.")
+	rootCmd.PersistentFlags().BoolVar(&cliArgs.PrintCurl, "print-curl", false, "Print the requests as curl commands instead of sending them, with secrets redacted.")
+
+	rootCmd.PersistentFlags().StringVarP(&cliArgs.Output, "output", "o", "", "Output format (choices: json, ndjson, csv, tsv, table, template, text) (default: table on a terminal, text otherwise)")
+	rootCmd.PersistentFlags().BoolVar(&cliArgs.FullHit, "full-hit", false, "Write full hits (_index, _id, _source, ...) instead of only _source with ndjson output and export.")
+	rootCmd.PersistentFlags().StringSliceVar(&cliArgs.Fields, "fields", nil, "Fields of each hit to fetch with the fields API, including runtime and unmapped fields, instead of the _source unless it is filtered, and the columns of csv/tsv/table output (default: all fields of the results).")
+	rootCmd.PersistentFlags().BoolVar(&cliArgs.NoHeader, "no-header", false, "Omit the header row of csv/tsv output.")
+	rootCmd.PersistentFlags().StringVar(&cliArgs.ArrayDelimiter, "array-delimiter", output.DefaultArrayDelimiter, "Delimiter joining array values in a csv/tsv/table cell.")
+	rootCmd.PersistentFlags().StringVar(&cliArgs.Template, "template", "", "Go text/template rendering each hit, e.g. '{{.timestamp}} {{.level}} {{.message}}' (implies -o template).")
+	rootCmd.PersistentFlags().StringVar(&cliArgs.TemplateFile, "template-file", "", "Path to a file containing the output template (implies -o template).")
+	rootCmd.PersistentFlags().BoolVar(&cliArgs.TemplateResponse, "template-response", false, "Render the template once per response instead of once per hit.")
 	rootCmd.PersistentFlags().StringVar(&cliArgs.OutputFile, "output-file", "", "Write output to a file instead of stdout.")
 	rootCmd.PersistentFlags().StringVarP(&cliArgs.JqPath, "jq", "j", "", "Apply a jq expression to the output.")
 
-	_ = rootCmd.MarkPersistentFlagRequired("node")
-	_ = rootCmd.MarkPersistentFlagRequired("index")
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return exitcode.Wrap(exitcode.Usage, err)
+	})
 
 	// Bind all persistent flags to viper automatically
 	rootCmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
@@ -117,7 +368,70 @@ func init() {
 
 }
 
-func InitConfig(cfgFile string, appName string, args *options.CliArgs) error {
+// usageArgs returns validate with its errors, such as an unknown command,
+// exiting with the Usage code.
+func usageArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		return exitcode.Wrap(exitcode.Usage, validate(cmd, args))
+	}
+}
+
+func InitConfig(cmd *cobra.Command, cfgFile string, appName string, args *options.CliArgs) error {
+	if err := loadConfig(cmd, cfgFile, appName, args); err != nil {
+		return err
+	}
+
+	return validation.ValidateCliArgs(*args)
+}
+
+// initConfigMatchAll loads and validates the configuration like InitConfig,
+// but matches all documents when no query is given.
+func initConfigMatchAll(cmd *cobra.Command) error {
+	if err := loadConfig(cmd, cfgFile, AppName, &cliArgs); err != nil {
+		return err
+	}
+	if !cliArgs.HasQuery() {
+		cliArgs.DSL = `{"query":{"match_all":{}}}`
+	}
+	return validation.ValidateCliArgs(cliArgs)
+}
+
+// loadConfig reads the config file, the selected context and environment into
+// the flags of cmd that were not given on the command line, and so into args.
+func loadConfig(cmd *cobra.Command, cfgFile string, appName string, args *options.CliArgs) error {
+	if err := readConfig(cfgFile, appName); err != nil {
+		return err
+	}
+	if err := useContext(); err != nil {
+		return err
+	}
+	if err := applyConfig(cmd.Flags()); err != nil {
+		return err
+	}
+	if args.PrintCurl {
+		args.DryRun = true
+	}
+	// the output columns are fetched with the fields API, instead of the
+	// _source unless it is filtered
+	args.FetchFields = args.OutputOptions.Fields
+	if debug, _ := cmd.Flags().GetBool("debug"); debug {
+		args.Verbose = max(args.Verbose, 2)
+	}
+	if args.DryRun {
+		// a dry run writes no results
+		args.OutputFile = ""
+	}
+	if args.SQLTranslate && args.Output == "" && args.Template == "" && args.TemplateFile == "" {
+		// the translation is a search request, not hits to render
+		args.Output = "json"
+	}
+	args.SetDefaultFormat()
+
+	return nil
+}
+
+// readConfig reads the config file and sets up the environment variables.
+func readConfig(cfgFile string, appName string) error {
 	if cfgFile != "" {
 		viper.SetConfigFile(cfgFile)
 	} else {
@@ -130,6 +444,7 @@ func InitConfig(cfgFile string, appName string, args *options.CliArgs) error {
 	}
 
 	viper.SetEnvPrefix(strings.ToUpper(appName))
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.AutomaticEnv()
 
 	if err := viper.ReadInConfig(); err != nil {
@@ -139,9 +454,73 @@ func InitConfig(cfgFile string, appName string, args *options.CliArgs) error {
 	} else {
 		fmt.Fprintf(os.Stderr, "Using config file: %s\n", viper.ConfigFileUsed())
 	}
-	if err := viper.Unmarshal(args); err != nil {
-		return fmt.Errorf("failed to unmarshal configuration: %w", err)
+	return nil
+}
+
+// currentContext returns the name of the selected context: --context or
+// ESQ_CONTEXT if set, the current-context of the config file otherwise.
+func currentContext() string {
+	if name := viper.GetString("context"); name != "" {
+		return name
 	}
+	return viper.GetString(config.CurrentContextKey)
+}
 
-	return validation.ValidateCliArgs(*args)
+// contexts returns the contexts of the config file by name, read from the
+// file itself since viper lowercases keys.
+func contexts() (map[string]map[string]any, error) {
+	return config.Contexts(viper.ConfigFileUsed())
+}
+
+// useContext merges the values of the selected context into the config, so
+// that they take precedence over top-level values of the config file, but
+// not over environment variables and flags.
+func useContext() error {
+	name := currentContext()
+	if name == "" {
+		return nil
+	}
+	all, err := contexts()
+	if err != nil {
+		return err
+	}
+	values, ok := all[name]
+	if !ok {
+		return fmt.Errorf("context '%s' not found in config file", name)
+	}
+	return viper.MergeConfigMap(values)
+}
+
+// applyConfig sets the flags not given on the command line from the config
+// file or environment, e.g. "time-field" from the key time-field or
+// time_field, or from ESQ_TIME_FIELD.
+func applyConfig(flags *pflag.FlagSet) error {
+	var err error
+	flags.VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Changed {
+			return
+		}
+		key := f.Name
+		if !viper.IsSet(key) {
+			key = strings.ReplaceAll(f.Name, "-", "_")
+			if !viper.IsSet(key) {
+				return
+			}
+		}
+		value := viper.GetString(key)
+		if slice, isSlice := f.Value.(pflag.SliceValue); isSlice {
+			if f.Value.Type() == "stringArray" {
+				// array values may contain commas, set them as-is
+				if setErr := slice.Replace(viper.GetStringSlice(key)); setErr != nil {
+					err = fmt.Errorf("invalid configuration value for '%s': %w", f.Name, setErr)
+				}
+				return
+			}
+			value = strings.Join(viper.GetStringSlice(key), ",")
+		}
+		if setErr := flags.Set(f.Name, value); setErr != nil {
+			err = fmt.Errorf("invalid configuration value for '%s': %w", f.Name, setErr)
+		}
+	})
+	return err
 }
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fa7ad/esq/internal/exitcode"
+	"github.com/fa7ad/esq/internal/options"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`index: top-index
+time-field: top-time
+output: ndjson
+current-context: Prod
+contexts:
+  Prod:
+    index: prod-index
+    time-field: prod-time
+  Local:
+    index: local-index
+`), 0600))
+
+	testCases := []struct {
+		name string
+		args []string
+		env  map[string]string
+
+		wantIndex     string
+		wantTimeField string
+		wantErr       string
+	}{
+		{
+			name:          "Current Context",
+			wantIndex:     "prod-index",
+			wantTimeField: "prod-time",
+		},
+		{
+			name:          "Context Flag",
+			args:          []string{"--context", "Local"},
+			wantIndex:     "local-index",
+			wantTimeField: "top-time",
+		},
+		{
+			name:          "Context from Environment",
+			env:           map[string]string{"ESQ_CONTEXT": "Local"},
+			wantIndex:     "local-index",
+			wantTimeField: "top-time",
+		},
+		{
+			name:          "Environment over Context",
+			env:           map[string]string{"ESQ_INDEX": "env-index"},
+			wantIndex:     "env-index",
+			wantTimeField: "prod-time",
+		},
+		{
+			name:          "Flag over Environment",
+			args:          []string{"--index", "flag-index"},
+			env:           map[string]string{"ESQ_INDEX": "env-index"},
+			wantIndex:     "flag-index",
+			wantTimeField: "prod-time",
+		},
+		{
+			name:    "Unknown Context",
+			args:    []string{"--context", "prod"},
+			wantErr: "context 'prod' not found in config file",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			var args options.CliArgs
+			cmd := &cobra.Command{Use: AppName}
+			flags := cmd.Flags()
+			flags.String("context", "", "")
+			flags.StringVarP(&args.Index, "index", "i", "", "")
+			flags.StringVar(&args.TimeField, "time-field", "", "")
+			flags.StringVarP(&args.Output, "output", "o", "", "")
+			require.NoError(t, flags.Parse(tc.args))
+			require.NoError(t, viper.BindPFlags(flags))
+
+			err := loadConfig(cmd, path, AppName, &args)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantIndex, args.Index)
+			assert.Equal(t, tc.wantTimeField, args.TimeField)
+			assert.Equal(t, "ndjson", args.Output, "top-level settings apply to every context")
+		})
+	}
+}
+
+func TestExecute_UsageErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("contexts:\n  local:\n    node: http://localhost:9200\n"), 0600))
+
+	testCases := []struct {
+		name string
+		args []string
+	}{
+		{"Unknown Command", []string{"extra-arg"}},
+		{"Unknown Flag", []string{"--no-such-flag"}},
+		{"Argument of Tail", []string{"tail", "extra-arg"}},
+		{"Argument of Export", []string{"export", "extra-arg"}},
+		{"Missing Context Name", []string{"config", "use-context"}},
+		{"Unknown Context", []string{"config", "use-context", "nope"}},
+		{"No Current Context", []string{"config", "current-context"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+			rootCmd.SetArgs(append([]string{"--config", path}, tc.args...))
+			t.Cleanup(func() { rootCmd.SetArgs(nil) })
+
+			err := rootCmd.ExecuteContext(context.Background())
+			require.Error(t, err)
+			assert.Equal(t, exitcode.Usage, exitcode.FromError(err), err.Error())
+		})
+	}
+}
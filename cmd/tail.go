@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fa7ad/esq/internal/esclient"
+	"github.com/fa7ad/esq/internal/exitcode"
+	"github.com/fa7ad/esq/internal/options"
+	"github.com/fa7ad/esq/internal/validation"
+)
+
+const DefaultTailInterval = 2 * time.Second
+
+var tailOpts options.TailOptions
+
+var tailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Print the latest matching documents, and follow new ones as they arrive.",
+	Long: fmt.Sprintf(`Print the latest --size documents matching the query, oldest first, like tail(1).
+
+With --follow (-F, as -f is --query-file), keep polling the index every --interval for documents
+newer than the last one seen and print them as they arrive, until interrupted with Ctrl-C.
+Documents are ordered by --time-field, detected from the index mapping if not set, then by
+--tiebreaker-field if set. Without a query, all
+documents are matched. Documents are rendered with the configured output format.
+
+Examples:
+	# Follow error logs of a service
+	%[1]s tail -n http://localhost:9200 -i 'logs-*' --kql "service:api and level:error" -F
+
+	# Follow all documents with a template, polling every 5 seconds
+	%[1]s tail -n http://localhost:9200 -i 'logs-*' -F --interval 5s --time-field @timestamp \
+		--template '{{get "@timestamp" .}} {{.message}}'
+`, AppName),
+	Args: usageArgs(cobra.NoArgs),
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := initConfigMatchAll(cmd); err != nil {
+			return exitcode.Wrap(exitcode.Usage, err)
+		}
+		return exitcode.Wrap(exitcode.Usage, validation.ValidateTailOptions(tailOpts, cliArgs.OutputOptions))
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		esClient, err := esclient.NewElasticsearchClient(cliArgs.AuthOptions, cliArgs.ElasticOptions)
+		if err != nil {
+			return fmt.Errorf("failed to create ES client: %w", err)
+		}
+
+		if err := esClient.ResolveTimeField(cmd.Context(), &cliArgs.ElasticOptions); err != nil {
+			return err
+		}
+
+		rw, err := cliArgs.NewResultWriter()
+		if err != nil {
+			return err
+		}
+
+		err = esClient.Tail(cmd.Context(), cliArgs.ElasticOptions, tailOpts, func(hits []any) error {
+			return rw.Write(hits)
+		})
+		closeErr := rw.Close()
+		if err != nil {
+			return fmt.Errorf("failed to tail: %w", err)
+		}
+		return closeErr
+	},
+}
+
+func init() {
+	tailCmd.Flags().BoolVarP(&tailOpts.Follow, "follow", "F", false, "Keep polling for new documents until interrupted.")
+	tailCmd.Flags().DurationVar(&tailOpts.Interval, "interval", DefaultTailInterval, "Time between polls when following.")
+	tailCmd.Flags().StringVar(&tailOpts.TiebreakerField, "tiebreaker-field", "", "Field ordering documents sharing a timestamp, e.g. a sequence number.")
+
+	rootCmd.AddCommand(tailCmd)
+}
@@ -0,0 +1,87 @@
+// Package config edits the esq configuration file.
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentContextKey is the config key naming the context used by default.
+const CurrentContextKey = "current-context"
+
+// ContextsKey is the config key of the contexts map.
+const ContextsKey = "contexts"
+
+// Contexts returns the contexts of the YAML config file at path by name. The
+// names keep their case, unlike the keys read by viper. There are none if
+// path is empty, e.g. when no config file was found.
+func Contexts(path string) (map[string]map[string]any, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	var doc struct {
+		Contexts map[string]map[string]any `yaml:"contexts"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing contexts of config file: %w", err)
+	}
+	return doc.Contexts, nil
+}
+
+// SetCurrentContext sets the current context in the YAML config file at path,
+// preserving the rest of the file, including comments.
+func SetCurrentContext(path, name string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("error parsing config file: %w", err)
+	}
+	if doc.Kind == 0 {
+		// empty file
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("error parsing config file: expected a mapping at the top level")
+	}
+
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}
+	updated := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == CurrentContextKey {
+			root.Content[i+1] = value
+			updated = true
+		}
+	}
+	if !updated {
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: CurrentContextKey}
+		root.Content = append([]*yaml.Node{key, value}, root.Content...)
+	}
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	if err := os.WriteFile(path, out.Bytes(), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	return nil
+}
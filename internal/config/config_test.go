@@ -0,0 +1,94 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetCurrentContext(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{
+			name:   "Replace current context",
+			config: "current-context: local\n# clusters\ncontexts:\n  local:\n    node: http://localhost:9200\n",
+			want:   "current-context: prod\n# clusters\ncontexts:\n  local:\n    node: http://localhost:9200\n",
+		},
+		{
+			name:   "Add current context",
+			config: "output: json\n",
+			want:   "current-context: prod\noutput: json\n",
+		},
+		{
+			name:   "Empty file",
+			config: "",
+			want:   "current-context: prod\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tc.config), 0600))
+
+			require.NoError(t, SetCurrentContext(path, "prod"))
+
+			got, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, string(got))
+		})
+	}
+}
+
+func TestContexts(t *testing.T) {
+	testCases := []struct {
+		name    string
+		config  string
+		want    map[string]map[string]any
+		wantErr bool
+	}{
+		{
+			name:   "Names keep their case",
+			config: "current-context: Prod\ncontexts:\n  Prod:\n    node: https://es.example.com\n    time-field: '@timestamp'\n  local:\n    node: http://localhost:9200\n",
+			want: map[string]map[string]any{
+				"Prod":  {"node": "https://es.example.com", "time-field": "@timestamp"},
+				"local": {"node": "http://localhost:9200"},
+			},
+		},
+		{
+			name:   "No contexts",
+			config: "output: json\n",
+		},
+		{
+			name:    "Invalid contexts",
+			config:  "contexts: [prod]\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tc.config), 0600))
+			got, err := Contexts(path)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	t.Run("No config file", func(t *testing.T) {
+		got, err := Contexts("")
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+}
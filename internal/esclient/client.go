@@ -1,37 +1,80 @@
 package esclient
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
+	"os"
 
 	"github.com/elastic/go-elasticsearch/v9"
+	"github.com/elastic/go-elasticsearch/v9/esapi"
+	"github.com/fa7ad/esq/internal/exitcode"
 	"github.com/fa7ad/esq/internal/options"
 )
 
+// searchFilterPath lists the response fields kept from a search response.
+var searchFilterPath = []string{
+	"hits.hits",
+	"hits.total",
+	"took",
+	"timed_out",
+	"_shards",
+	"aggregations",
+}
+
 // esClient represents an Elasticsearch client.
 type esClient struct {
 	client *elasticsearch.Client
+	// dryRun is set if requests are printed instead of sent.
+	dryRun bool
 }
 
 // NewElasticsearchClient creates a new Elasticsearch client.
 func NewElasticsearchClient(authOpts options.AuthOptions, opts options.ElasticOptions) (*esClient, error) {
+	transport, err := newTransport(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := elasticsearch.Config{
-		Addresses: []string{opts.Node},
+		Addresses: opts.Nodes,
+		CloudID:   opts.CloudID,
+		Transport: transport,
 	}
 
+	// nothing is sent in a dry run: do not run credential commands, read
+	// secrets or prompt for a password
+	if opts.DryRun {
+		authOpts.PlaceholderCredentials()
+	} else if err := authOpts.ResolveCredentials(os.Stdin); err != nil {
+		return nil, exitcode.Wrap(exitcode.Auth, err)
+	}
 	authOpts.UpdateConfig(&cfg)
+	opts.TransportOptions.UpdateConfig(&cfg)
+
+	if opts.Verbose > 0 {
+		cfg.Logger = newTraceLogger(os.Stderr, opts.Verbose, opts.TransportOptions)
+	}
+
+	if opts.DryRun {
+		cfg.Logger = nil
+		cfg.Transport = &dryRunTransport{w: os.Stdout, curl: opts.PrintCurl}
+		cfg.DisableRetry = true
+		cfg.DiscoverNodesOnStart = false
+		cfg.DiscoverNodesInterval = 0
+	}
 
 	client, err := elasticsearch.NewClient(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Elasticsearch client: %w", err)
 	}
 
-	return &esClient{client}, nil
+	return &esClient{client: client, dryRun: opts.DryRun}, nil
 }
 
 // Search executes a search query against a specified index.
-func (c *esClient) Search(esOpts options.ElasticOptions) (map[string]any, error) {
+func (c *esClient) Search(ctx context.Context, esOpts options.ElasticOptions) (map[string]any, error) {
 	queryBody, err := esOpts.ToQueryBody()
 	if err != nil {
 		return nil, err
@@ -39,28 +82,66 @@ func (c *esClient) Search(esOpts options.ElasticOptions) (map[string]any, error)
 
 	// Execute the search
 	res, err := c.client.Search(
+		c.client.Search.WithContext(ctx),
 		c.client.Search.WithIndex(esOpts.Index),
 		c.client.Search.WithBody(queryBody),
 		c.client.Search.WithSize(esOpts.Size),
 		c.client.Search.WithTrackTotalHits(true),
 		c.client.Search.WithPretty(),
-		c.client.Search.WithFilterPath(
-			"hits.hits",
-			"took",
-			"timed_out",
-			"_shards",
-		),
+		c.client.Search.WithFilterPath(searchFilterPath...),
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("elasticsearch search failed: %w", err)
 	}
+
+	return decodeSearchResponse(res)
+}
+
+// Count counts the documents matching the query without fetching them, and
+// returns the count as {"count": n}.
+func (c *esClient) Count(ctx context.Context, esOpts options.ElasticOptions) (map[string]any, error) {
+	body, err := esOpts.SearchRequestBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize query options: %w", err)
+	}
+	data, err := json.Marshal(map[string]any{"query": body.Query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal query: %w", err)
+	}
+
+	res, err := c.client.Count(
+		c.client.Count.WithContext(ctx),
+		c.client.Count.WithIndex(esOpts.Index),
+		c.client.Count.WithBody(bytes.NewReader(data)),
+		c.client.Count.WithFilterPath("count"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch count failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError("count", res)
+	}
+
+	var r map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("failed to parse count response body: %w", err)
+	}
+	return r, nil
+}
+
+// decodeSearchResponse decodes a search response into a stable envelope: the
+// array of hits under "hits", even if empty, and the total hit count, if
+// tracked, under "total" as {"value": ..., "relation": "eq" or "gte"}.
+// Timeouts and shard failures of a successful response are reported as warnings.
+func decodeSearchResponse(res *esapi.Response) (map[string]any, error) {
 	defer res.Body.Close()
 
 	// Check for Elasticsearch-specific errors
 	if res.IsError() {
-		bodyBytes, _ := io.ReadAll(res.Body)
-		return nil, fmt.Errorf("search error: [%s] %s", res.Status(), string(bodyBytes))
+		return nil, responseError("search", res)
 	}
 
 	// Decode the JSON response into a map
@@ -68,12 +149,18 @@ func (c *esClient) Search(esOpts options.ElasticOptions) (map[string]any, error)
 	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
 		return nil, fmt.Errorf("failed to parse search response body: %w", err)
 	}
+	warnPartialResults(os.Stderr, r)
 
+	hitsArray := []any{}
 	if hits, found := r["hits"].(map[string]any); found {
-		if hitsArray, ok := hits["hits"].([]any); ok {
-			r["hits"] = hitsArray // Replace "hits" with the array of hits
+		if array, ok := hits["hits"].([]any); ok {
+			hitsArray = array
+		}
+		if total, ok := hits["total"].(map[string]any); ok {
+			r["total"] = total
 		}
 	}
+	r["hits"] = hitsArray // Replace "hits" with the array of hits
 
 	return r, nil
 }
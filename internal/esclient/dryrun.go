@@ -0,0 +1,136 @@
+package esclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrDryRun is returned by requests that were printed instead of sent.
+var ErrDryRun = errors.New("dry run, request not sent")
+
+// redacted replaces secrets in printed requests.
+const redacted = "REDACTED"
+
+// dryRunPITID is the point in time id answered to dry run requests opening one,
+// so that the searches of the point in time are printed too.
+const dryRunPITID = "dry-run-pit-id"
+
+// dryRunTransport prints requests as JSON, or as curl commands, instead of
+// sending them.
+type dryRunTransport struct {
+	mu   sync.Mutex
+	w    io.Writer
+	curl bool
+}
+
+// dryRunRequest is the JSON form of a printed request.
+type dryRunRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Path    string            `json:"path"`
+	Params  map[string]string `json:"params,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+
+	var out string
+	if t.curl {
+		out = curlCommand(req, body)
+	} else {
+		out = requestJSON(req, body)
+	}
+	t.mu.Lock()
+	fmt.Fprintln(t.w, out)
+	t.mu.Unlock()
+
+	if req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/_pit") {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"X-Elastic-Product": {"Elasticsearch"}, "Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"id":"` + dryRunPITID + `"}`)),
+			Request:    req,
+		}, nil
+	}
+	return nil, ErrDryRun
+}
+
+// requestJSON renders a request as indented JSON, with secrets redacted.
+func requestJSON(req *http.Request, body []byte) string {
+	r := dryRunRequest{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Path:    req.URL.Path,
+		Params:  map[string]string{},
+		Headers: map[string]string{},
+	}
+	for key, values := range req.URL.Query() {
+		r.Params[key] = strings.Join(values, ",")
+	}
+	if auth := redactAuthorization(req.Header.Get("Authorization")); auth != "" {
+		r.Headers["Authorization"] = auth
+	}
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		r.Headers["Content-Type"] = contentType
+	}
+	if json.Valid(body) {
+		r.Body = body
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(r)
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// curlCommand renders a request as a curl command, with secrets redacted.
+// Basic authentication is rendered as the username only, for curl to prompt
+// for the password.
+func curlCommand(req *http.Request, body []byte) string {
+	parts := []string{"curl", "-X", req.Method, shellQuote(req.URL.String())}
+	if username, _, ok := req.BasicAuth(); ok {
+		parts = append(parts, "-u", shellQuote(username))
+	} else if auth := redactAuthorization(req.Header.Get("Authorization")); auth != "" {
+		parts = append(parts, "-H", shellQuote("Authorization: "+auth))
+	}
+	if len(body) > 0 {
+		var compact bytes.Buffer
+		if json.Compact(&compact, body) == nil {
+			body = compact.Bytes()
+		}
+		parts = append(parts, "-H", shellQuote("Content-Type: application/json"), "-d", shellQuote(string(body)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// redactAuthorization keeps the scheme of an Authorization header, such as
+// "ApiKey" or "Bearer", and redacts the credentials.
+func redactAuthorization(auth string) string {
+	if auth == "" {
+		return ""
+	}
+	scheme, _, _ := strings.Cut(auth, " ")
+	return scheme + " " + redacted
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
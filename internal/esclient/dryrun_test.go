@@ -0,0 +1,49 @@
+package esclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurlCommand(t *testing.T) {
+	newRequest := func(method, url, body string, header http.Header) *http.Request {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		return req
+	}
+	basic := newRequest(http.MethodPost, "http://localhost:9200/logs/_search?size=10", "", nil)
+	basic.SetBasicAuth("elastic", "changeme")
+
+	testCases := []struct {
+		name string
+		req  *http.Request
+		body string
+		want string
+	}{
+		{
+			name: "Basic auth prompts for the password",
+			req:  basic,
+			body: "{\n  \"query\": {\"match\": {\"msg\": \"it's\"}}\n}",
+			want: `curl -X POST 'http://localhost:9200/logs/_search?size=10' -u 'elastic' -H 'Content-Type: application/json' -d '{"query":{"match":{"msg":"it'\''s"}}}'`,
+		},
+		{
+			name: "API key is redacted",
+			req:  newRequest(http.MethodDelete, "http://localhost:9200/_pit", "", http.Header{"Authorization": {"APIKey c2VjcmV0"}}),
+			want: `curl -X DELETE 'http://localhost:9200/_pit' -H 'Authorization: APIKey REDACTED'`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := curlCommand(tc.req, []byte(tc.body))
+			assert.Equal(t, tc.want, got)
+			assert.NotContains(t, got, "changeme")
+			assert.NotContains(t, got, "c2VjcmV0")
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package esclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fa7ad/esq/internal/options"
+)
+
+// EQL runs the EQL query of esOpts with the EQL search API. The matching
+// events, or the sequences or samples of events, are returned as the hits of
+// a search response; each sequence is {"join_keys": [...], "events": [...]}.
+func (c *esClient) EQL(ctx context.Context, esOpts options.ElasticOptions) (map[string]any, error) {
+	body, err := esOpts.EQLRequestBody()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.client.EqlSearch(
+		esOpts.Index,
+		bytes.NewReader(body),
+		c.client.EqlSearch.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch EQL search failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError("EQL search", res)
+	}
+
+	var r map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("failed to parse EQL response body: %w", err)
+	}
+	warnPartialResults(os.Stderr, r)
+	return eqlResponse(r), nil
+}
+
+// eqlResponse moves the events or sequences of an EQL response to "hits", and
+// its total hit count to "total", like decodeSearchResponse.
+func eqlResponse(r map[string]any) map[string]any {
+	hitsArray := []any{}
+	if hits, ok := r["hits"].(map[string]any); ok {
+		if events, ok := hits["events"].([]any); ok {
+			hitsArray = events
+		}
+		if sequences, ok := hits["sequences"].([]any); ok {
+			hitsArray = sequences
+		}
+		if total, ok := hits["total"].(map[string]any); ok {
+			r["total"] = total
+		}
+	}
+	r["hits"] = hitsArray
+	return r
+}
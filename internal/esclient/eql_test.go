@@ -0,0 +1,47 @@
+package esclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEQLResponse(t *testing.T) {
+	testCases := []struct {
+		name      string
+		response  string
+		wantHits  int
+		wantTotal any
+	}{
+		{
+			name:      "Events",
+			response:  `{"took":3,"timed_out":false,"hits":{"total":{"value":2,"relation":"eq"},"events":[{"_id":"1","_source":{}},{"_id":"2","_source":{}}]}}`,
+			wantHits:  2,
+			wantTotal: map[string]any{"value": 2.0, "relation": "eq"},
+		},
+		{
+			name:      "Sequences",
+			response:  `{"took":3,"timed_out":false,"hits":{"total":{"value":1,"relation":"eq"},"sequences":[{"join_keys":["web-1"],"events":[{"_id":"1"},{"_id":"2"}]}]}}`,
+			wantHits:  1,
+			wantTotal: map[string]any{"value": 1.0, "relation": "eq"},
+		},
+		{
+			name:     "No Matches",
+			response: `{"took":3,"timed_out":false,"hits":{}}`,
+			wantHits: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var r map[string]any
+			require.NoError(t, json.Unmarshal([]byte(tc.response), &r))
+
+			got := eqlResponse(r)
+			assert.Len(t, got["hits"], tc.wantHits)
+			assert.Equal(t, tc.wantTotal, got["total"])
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package esclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v9/esapi"
+
+	"github.com/fa7ad/esq/internal/exitcode"
+)
+
+// ErrorCause is an error, or the cause of an error, reported by Elasticsearch.
+type ErrorCause struct {
+	Type     string      `json:"type"`
+	Reason   string      `json:"reason"`
+	Index    string      `json:"index,omitempty"`
+	CausedBy *ErrorCause `json:"caused_by,omitempty"`
+}
+
+// ShardFailure is the failure of a search on one shard.
+type ShardFailure struct {
+	Shard  int        `json:"shard"`
+	Index  string     `json:"index"`
+	Node   string     `json:"node"`
+	Reason ErrorCause `json:"reason"`
+}
+
+// ResponseError is an error response of Elasticsearch, such as
+// {"error": {"type": ..., "reason": ..., "root_cause": [...]}, "status": 400}.
+type ResponseError struct {
+	// Op is the failed operation, e.g. "search".
+	Op         string
+	StatusCode int
+	Status     string
+
+	ErrorCause
+	RootCauses   []ErrorCause
+	FailedShards []ShardFailure
+
+	// Body is the response body, if it is not an error envelope.
+	Body string
+}
+
+// Error returns the error type and reason, followed by its distinct causes,
+// one per line. Query parse errors point at the offending position.
+func (e *ResponseError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s error: [%s]", e.Op, e.Status)
+	if e.Type == "" && e.Reason == "" {
+		if body := strings.TrimSpace(e.Body); body != "" {
+			b.WriteString(" " + body)
+		}
+		return b.String()
+	}
+	b.WriteString(" " + e.ErrorCause.String())
+
+	seen := map[string]bool{e.ErrorCause.String(): true}
+	addCause := func(cause *ErrorCause, where string) {
+		for ; cause != nil; cause = cause.CausedBy {
+			line := cause.String()
+			if seen[line] {
+				continue
+			}
+			seen[line] = true
+			b.WriteString("\n  caused by: " + line)
+			if where != "" {
+				b.WriteString(" (" + where + ")")
+			}
+			if pointer := queryPointer(cause.Reason); pointer != "" {
+				b.WriteString("\n" + pointer)
+			}
+		}
+	}
+
+	for i := range e.RootCauses {
+		addCause(&e.RootCauses[i], indexLocation(e.RootCauses[i].Index, -1))
+	}
+	for i := range e.FailedShards {
+		failure := &e.FailedShards[i]
+		addCause(&failure.Reason, indexLocation(failure.Index, failure.Shard))
+	}
+	addCause(e.CausedBy, "")
+	return b.String()
+}
+
+// ExitCode returns Auth for rejected credentials, Connection for an
+// unavailable cluster, Incomplete for timeouts and Query otherwise.
+func (e *ResponseError) ExitCode() exitcode.Code {
+	switch e.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return exitcode.Auth
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return exitcode.Connection
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return exitcode.Incomplete
+	}
+	return exitcode.Query
+}
+
+// String returns the cause as "type: reason".
+func (c ErrorCause) String() string {
+	if c.Type == "" {
+		return c.Reason
+	}
+	return c.Type + ": " + c.Reason
+}
+
+func indexLocation(index string, shard int) string {
+	switch {
+	case index == "":
+		return ""
+	case shard < 0:
+		return "index " + index
+	default:
+		return fmt.Sprintf("index %s, shard %d", index, shard)
+	}
+}
+
+// queryParseError matches the reason of a failed query_string query, such
+// as "Cannot parse 'level:(': Encountered "<EOF>" at line 1, column 7.".
+var queryParseError = regexp.MustCompile(`(?s)^Cannot parse '(.*)': .*at line (\d+), column (\d+)`)
+
+// queryPointer returns the line of a query that failed to parse, with a caret
+// under the offending position, or an empty string if the reason is not a
+// query parse error.
+func queryPointer(reason string) string {
+	m := queryParseError.FindStringSubmatch(reason)
+	if m == nil {
+		return ""
+	}
+	line, _ := strconv.Atoi(m[2])
+	column, _ := strconv.Atoi(m[3])
+	lines := strings.Split(m[1], "\n")
+	if line < 1 || line > len(lines) || column < 1 {
+		return ""
+	}
+	text := lines[line-1]
+	column = min(column, len(text)+1)
+	return "      " + text + "\n      " + strings.Repeat(" ", column-1) + "^"
+}
+
+// responseError reads the error response of an operation into a *ResponseError.
+func responseError(op string, res *esapi.Response) error {
+	body, _ := io.ReadAll(res.Body)
+	e := &ResponseError{Op: op, StatusCode: res.StatusCode, Status: res.Status(), Body: string(body)}
+
+	var envelope struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil || len(envelope.Error) == 0 {
+		return e
+	}
+
+	var details struct {
+		ErrorCause
+		RootCause    []ErrorCause   `json:"root_cause"`
+		FailedShards []ShardFailure `json:"failed_shards"`
+	}
+	if err := json.Unmarshal(envelope.Error, &details); err != nil {
+		// some errors are a plain string, e.g. {"error": "Incorrect HTTP method"}
+		var reason string
+		if json.Unmarshal(envelope.Error, &reason) == nil {
+			e.Reason = reason
+		}
+		return e
+	}
+	e.ErrorCause = details.ErrorCause
+	e.RootCauses = details.RootCause
+	e.FailedShards = details.FailedShards
+	return e
+}
+
+// IsPartial reports whether the results of a search response are partial,
+// because shards failed or the search timed out, or of an ES|QL response
+// flagged as partial.
+func IsPartial(response map[string]any) bool {
+	if timedOut, _ := response["timed_out"].(bool); timedOut {
+		return true
+	}
+	if partial, _ := response["is_partial"].(bool); partial {
+		return true
+	}
+	shards, _ := response["_shards"].(map[string]any)
+	failed, _ := shards["failed"].(float64)
+	return failed > 0
+}
+
+// warnPartialResults writes a warning if a successful search response timed
+// out or is flagged as partial, and for each distinct shard failure, which
+// make its results partial.
+func warnPartialResults(w io.Writer, response map[string]any) {
+	if timedOut, _ := response["timed_out"].(bool); timedOut {
+		fmt.Fprintln(w, "Warning: the search timed out, results are partial")
+	}
+	if partial, _ := response["is_partial"].(bool); partial {
+		fmt.Fprintln(w, "Warning: the query did not complete on all clusters, results are partial")
+	}
+
+	shards, ok := response["_shards"].(map[string]any)
+	if !ok {
+		return
+	}
+	failed, _ := shards["failed"].(float64)
+	if failed == 0 {
+		return
+	}
+	total, _ := shards["total"].(float64)
+
+	data, err := json.Marshal(shards["failures"])
+	if err != nil {
+		return
+	}
+	var failures []ShardFailure
+	_ = json.Unmarshal(data, &failures)
+
+	prefix := fmt.Sprintf("Warning: %d of %d shards failed, results are partial", int(failed), int(total))
+	if len(failures) == 0 {
+		fmt.Fprintln(w, prefix)
+		return
+	}
+	seen := map[string]bool{}
+	for _, failure := range failures {
+		reason := failure.Reason.String()
+		if seen[reason] {
+			continue
+		}
+		seen[reason] = true
+		fmt.Fprintf(w, "%s: %s (%s)\n", prefix, reason, indexLocation(failure.Index, failure.Shard))
+	}
+}
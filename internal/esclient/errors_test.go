@@ -0,0 +1,131 @@
+package esclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v9/esapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fa7ad/esq/internal/exitcode"
+)
+
+func TestResponseError(t *testing.T) {
+	newResponse := func(status int, body string) *esapi.Response {
+		return &esapi.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}
+	}
+
+	testCases := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+		code   exitcode.Code
+	}{
+		{
+			name:   "Query Parse Error",
+			status: http.StatusBadRequest,
+			body: `{"error":{"root_cause":[{"type":"query_shard_exception","reason":"Failed to parse query [level:(]","index":"logs"}],
+				"type":"search_phase_execution_exception","reason":"all shards failed","phase":"query",
+				"failed_shards":[{"shard":0,"index":"logs","node":"n1","reason":{"type":"query_shard_exception","reason":"Failed to parse query [level:(]","index":"logs",
+					"caused_by":{"type":"parse_exception","reason":"Cannot parse 'level:(': Encountered \"<EOF>\" at line 1, column 7.",
+						"caused_by":{"type":"parse_exception","reason":"Encountered \"<EOF>\" at line 1, column 7."}}}}]},"status":400}`,
+			want: "search error: [400 Bad Request] search_phase_execution_exception: all shards failed\n" +
+				"  caused by: query_shard_exception: Failed to parse query [level:(] (index logs)\n" +
+				"  caused by: parse_exception: Cannot parse 'level:(': Encountered \"<EOF>\" at line 1, column 7. (index logs, shard 0)\n" +
+				"      level:(\n" +
+				"            ^\n" +
+				"  caused by: parse_exception: Encountered \"<EOF>\" at line 1, column 7. (index logs, shard 0)",
+			code: exitcode.Query,
+		},
+		{
+			name:   "Missing Index",
+			status: http.StatusNotFound,
+			body: `{"error":{"root_cause":[{"type":"index_not_found_exception","reason":"no such index [nope]","index":"nope"}],
+				"type":"index_not_found_exception","reason":"no such index [nope]","index":"nope"},"status":404}`,
+			want: "search error: [404 Not Found] index_not_found_exception: no such index [nope]",
+			code: exitcode.Query,
+		},
+		{
+			name:   "String Error",
+			status: http.StatusMethodNotAllowed,
+			body:   `{"error":"Incorrect HTTP method for uri [/logs/_search]","status":405}`,
+			want:   "search error: [405 Method Not Allowed] Incorrect HTTP method for uri [/logs/_search]",
+			code:   exitcode.Query,
+		},
+		{
+			name:   "Not an Error Envelope",
+			status: http.StatusBadGateway,
+			body:   "<html>Bad Gateway</html>\n",
+			want:   "search error: [502 Bad Gateway] <html>Bad Gateway</html>",
+			code:   exitcode.Connection,
+		},
+		{
+			name:   "Security Exception",
+			status: http.StatusUnauthorized,
+			body:   `{"error":{"root_cause":[{"type":"security_exception","reason":"unable to authenticate user [elastic]"}],"type":"security_exception","reason":"unable to authenticate user [elastic]"},"status":401}`,
+			want:   "search error: [401 Unauthorized] security_exception: unable to authenticate user [elastic]",
+			code:   exitcode.Auth,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := responseError("search", newResponse(tc.status, tc.body))
+
+			var responseErr *ResponseError
+			require.ErrorAs(t, err, &responseErr)
+			assert.Equal(t, tc.status, responseErr.StatusCode)
+			assert.Equal(t, tc.want, err.Error())
+			assert.Equal(t, tc.code, exitcode.FromError(err))
+		})
+	}
+}
+
+func TestWarnPartialResults(t *testing.T) {
+	testCases := []struct {
+		name     string
+		response string
+		want     string
+	}{
+		{
+			name:     "No Failures",
+			response: `{"_shards":{"total":3,"successful":3,"skipped":0,"failed":0}}`,
+			want:     "",
+		},
+		{
+			name: "Distinct Failures",
+			response: `{"_shards":{"total":3,"successful":1,"skipped":0,"failed":2,"failures":[
+				{"shard":1,"index":"logs-a","node":"n1","reason":{"type":"illegal_argument_exception","reason":"Text fields are not optimised for sorting"}},
+				{"shard":2,"index":"logs-b","node":"n1","reason":{"type":"illegal_argument_exception","reason":"Text fields are not optimised for sorting"}}]}}`,
+			want: "Warning: 2 of 3 shards failed, results are partial: illegal_argument_exception: Text fields are not optimised for sorting (index logs-a, shard 1)\n",
+		},
+		{
+			name:     "Failures without Details",
+			response: `{"_shards":{"total":5,"successful":4,"skipped":0,"failed":1}}`,
+			want:     "Warning: 1 of 5 shards failed, results are partial\n",
+		},
+		{
+			name:     "Timed Out",
+			response: `{"timed_out":true,"_shards":{"total":1,"successful":1,"skipped":0,"failed":0}}`,
+			want:     "Warning: the search timed out, results are partial\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var response map[string]any
+			require.NoError(t, json.Unmarshal([]byte(tc.response), &response))
+
+			var buf bytes.Buffer
+			warnPartialResults(&buf, response)
+			assert.Equal(t, tc.want, buf.String())
+			assert.Equal(t, tc.want != "", IsPartial(response))
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package esclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fa7ad/esq/internal/options"
+)
+
+// ESQL runs the ES|QL query of esOpts with the _query API. Its rows are
+// returned as the hits of a search response, with its columns.
+func (c *esClient) ESQL(ctx context.Context, esOpts options.ElasticOptions) (map[string]any, error) {
+	body, err := esOpts.ESQLRequestBody()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.client.EsqlQuery(
+		bytes.NewReader(body),
+		c.client.EsqlQuery.WithContext(ctx),
+		c.client.EsqlQuery.WithFormat("json"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch ES|QL query failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError("ES|QL query", res)
+	}
+
+	var r map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("failed to parse ES|QL response body: %w", err)
+	}
+	warnPartialResults(os.Stderr, r)
+	return tabularResponse(r, "values"), nil
+}
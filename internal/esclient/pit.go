@@ -0,0 +1,159 @@
+package esclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v9/typedapi/types"
+	"github.com/fa7ad/esq/internal/options"
+)
+
+const (
+	// pitKeepAlive is how long the point in time is kept alive between pages.
+	pitKeepAlive = "1m"
+	// pitCloseTimeout bounds the request closing the point in time, which is
+	// sent even after the search context was cancelled.
+	pitCloseTimeout = 10 * time.Second
+)
+
+// PageHandler receives each page of search results, shaped like the result of Search.
+type PageHandler func(page map[string]any) error
+
+// SearchAll pages through every document matching the query, up to esOpts.Limit
+// documents if set. It opens a point in time on the index, pages with
+// search_after and passes each page to handle. The point in time is closed when
+// paging ends, including when ctx is cancelled.
+func (c *esClient) SearchAll(ctx context.Context, esOpts options.ElasticOptions, handle PageHandler) error {
+	body, err := esOpts.SearchRequestBody()
+	if err != nil {
+		return fmt.Errorf("failed to normalize query options: %w", err)
+	}
+
+	pitID, err := c.openPointInTime(ctx, esOpts.Index)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// the PIT id may change between pages, always close the latest one
+		c.closePointInTime(pitID)
+	}()
+
+	return c.pageThrough(ctx, body, &pitID, esOpts.Size, esOpts.Limit, handle)
+}
+
+// pageThrough pages with search_after through the point in time, updating
+// pitID as Elasticsearch returns new ids. A limit of zero fetches all documents.
+func (c *esClient) pageThrough(ctx context.Context, body *types.SearchRequestBody, pitID *string, pageSize, limit int, handle PageHandler) error {
+	if len(body.Sort) == 0 {
+		// _shard_doc is the cheapest total order for a point in time
+		body.Sort = []types.SortCombinations{"_shard_doc"}
+	}
+
+	fetched := 0
+	for {
+		size := pageSize
+		if limit > 0 {
+			size = min(size, limit-fetched)
+		}
+
+		body.Pit = &types.PointInTimeReference{Id: *pitID, KeepAlive: pitKeepAlive}
+		// the total hit count is only tracked once, on the first page
+		page, err := c.searchPage(ctx, body, size, fetched == 0)
+		if err != nil {
+			return err
+		}
+		if id, ok := page["pit_id"].(string); ok && id != "" {
+			*pitID = id
+			delete(page, "pit_id")
+		}
+
+		hits, _ := page["hits"].([]any)
+		if len(hits) == 0 {
+			return nil
+		}
+		if err := handle(page); err != nil {
+			return err
+		}
+
+		fetched += len(hits)
+		if len(hits) < size || (limit > 0 && fetched >= limit) {
+			return nil
+		}
+
+		last, _ := hits[len(hits)-1].(map[string]any)
+		sortValues, ok := last["sort"].([]any)
+		if !ok {
+			return fmt.Errorf("search response is missing sort values needed to fetch the next page")
+		}
+		body.SearchAfter = make([]types.FieldValue, 0, len(sortValues))
+		for _, v := range sortValues {
+			body.SearchAfter = append(body.SearchAfter, v)
+		}
+	}
+}
+
+// searchPage fetches a single page of a point in time search.
+func (c *esClient) searchPage(ctx context.Context, body *types.SearchRequestBody, size int, trackTotalHits bool) (map[string]any, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal query: %w", err)
+	}
+
+	// requests against a point in time must not name an index
+	res, err := c.client.Search(
+		c.client.Search.WithContext(ctx),
+		c.client.Search.WithBody(bytes.NewReader(data)),
+		c.client.Search.WithSize(size),
+		c.client.Search.WithTrackTotalHits(trackTotalHits),
+		c.client.Search.WithFilterPath(append(searchFilterPath, "pit_id")...),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch search failed: %w", err)
+	}
+
+	return decodeSearchResponse(res)
+}
+
+// openPointInTime opens a point in time on the index and returns its id.
+func (c *esClient) openPointInTime(ctx context.Context, index string) (string, error) {
+	res, err := c.client.OpenPointInTime(
+		[]string{index},
+		pitKeepAlive,
+		c.client.OpenPointInTime.WithContext(ctx),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to open point in time: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return "", responseError("open point in time", res)
+	}
+
+	var r struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return "", fmt.Errorf("failed to parse point in time response body: %w", err)
+	}
+	return r.ID, nil
+}
+
+// closePointInTime releases the point in time. Failures are ignored, the point
+// in time expires on its own after pitKeepAlive.
+func (c *esClient) closePointInTime(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), pitCloseTimeout)
+	defer cancel()
+
+	body, _ := json.Marshal(map[string]string{"id": id})
+	res, err := c.client.ClosePointInTime(
+		c.client.ClosePointInTime.WithContext(ctx),
+		c.client.ClosePointInTime.WithBody(bytes.NewReader(body)),
+	)
+	if err == nil {
+		res.Body.Close()
+	}
+}
@@ -0,0 +1,196 @@
+package esclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fa7ad/esq/internal/options"
+)
+
+// newTestClient returns a client of a stub Elasticsearch served by handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *esClient {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{srv.URL}, DisableRetry: true})
+	require.NoError(t, err)
+	return &esClient{client: client}
+}
+
+// pitCluster is a stub Elasticsearch paging through docs with a point in
+// time, whose id rotates on every page.
+type pitCluster struct {
+	docs int
+
+	mu          sync.Mutex
+	pitIDs      []string
+	searchAfter []any
+	sizes       []int
+	closed      []string
+}
+
+func (c *pitCluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/_pit"):
+		fmt.Fprint(w, `{"id":"pit-0"}`)
+	case r.Method == http.MethodDelete && r.URL.Path == "/_pit":
+		var body struct{ ID string }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		c.closed = append(c.closed, body.ID)
+		fmt.Fprint(w, `{"succeeded":true}`)
+	case r.URL.Path == "/_search":
+		var body struct {
+			Pit         struct{ ID string }
+			SearchAfter []float64 `json:"search_after"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+		c.pitIDs = append(c.pitIDs, body.Pit.ID)
+		c.sizes = append(c.sizes, size)
+
+		from := 0
+		if len(body.SearchAfter) > 0 {
+			c.searchAfter = append(c.searchAfter, body.SearchAfter[0])
+			from = int(body.SearchAfter[0]) + 1
+		} else {
+			c.searchAfter = append(c.searchAfter, nil)
+		}
+		hits := []any{}
+		for i := from; i < min(from+size, c.docs); i++ {
+			hits = append(hits, map[string]any{"_id": strconv.Itoa(i), "sort": []any{i}})
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"pit_id": fmt.Sprintf("pit-%d", len(c.pitIDs)),
+			"took":   1,
+			"hits":   map[string]any{"total": map[string]any{"value": c.docs, "relation": "eq"}, "hits": hits},
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"unexpected request"}`)
+	}
+}
+
+func TestSearchAll(t *testing.T) {
+	errHandler := errors.New("handler failed")
+
+	testCases := []struct {
+		name  string
+		size  int
+		limit int
+		// failAt and cancelAt make the handler of that page fail, or cancel
+		// the context, 1-based
+		failAt   int
+		cancelAt int
+
+		wantPages       [][]string
+		wantSearchAfter []any
+		wantSizes       []int
+		wantErr         error
+	}{
+		{
+			name:            "All Pages",
+			size:            2,
+			wantPages:       [][]string{{"0", "1"}, {"2", "3"}, {"4"}},
+			wantSearchAfter: []any{nil, 1.0, 3.0},
+			wantSizes:       []int{2, 2, 2},
+		},
+		{
+			name:            "Full Last Page",
+			size:            5,
+			wantPages:       [][]string{{"0", "1", "2", "3", "4"}},
+			wantSearchAfter: []any{nil, 4.0},
+			wantSizes:       []int{5, 5},
+		},
+		{
+			name:            "Limit",
+			size:            2,
+			limit:           3,
+			wantPages:       [][]string{{"0", "1"}, {"2"}},
+			wantSearchAfter: []any{nil, 1.0},
+			wantSizes:       []int{2, 1},
+		},
+		{
+			name:            "Handler Error",
+			size:            2,
+			failAt:          2,
+			wantPages:       [][]string{{"0", "1"}, {"2", "3"}},
+			wantSearchAfter: []any{nil, 1.0},
+			wantSizes:       []int{2, 2},
+			wantErr:         errHandler,
+		},
+		{
+			name:            "Context Cancelled",
+			size:            2,
+			cancelAt:        1,
+			wantPages:       [][]string{{"0", "1"}},
+			wantSearchAfter: []any{nil},
+			wantSizes:       []int{2},
+			wantErr:         context.Canceled,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := &pitCluster{docs: 5}
+			c := newTestClient(t, cluster.ServeHTTP)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			esOpts := options.ElasticOptions{Index: "logs", QueryOptions: options.QueryOptions{DSL: `{"match_all":{}}`, Size: tc.size, Limit: tc.limit}}
+			var pages [][]string
+			err := c.SearchAll(ctx, esOpts, func(page map[string]any) error {
+				assert.NotContains(t, page, "pit_id")
+				var ids []string
+				for _, hit := range page["hits"].([]any) {
+					ids = append(ids, hit.(map[string]any)["_id"].(string))
+				}
+				pages = append(pages, ids)
+				if len(pages) == tc.cancelAt {
+					cancel()
+				}
+				if len(pages) == tc.failAt {
+					return errHandler
+				}
+				return nil
+			})
+
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantPages, pages)
+			assert.Equal(t, tc.wantSearchAfter, cluster.searchAfter)
+			assert.Equal(t, tc.wantSizes, cluster.sizes)
+
+			// each page searches the id returned by the previous one, and the
+			// latest id is closed
+			wantIDs := make([]string, len(cluster.sizes))
+			for i := range wantIDs {
+				wantIDs[i] = fmt.Sprintf("pit-%d", i)
+			}
+			assert.Equal(t, wantIDs, cluster.pitIDs)
+			assert.Equal(t, []string{fmt.Sprintf("pit-%d", len(cluster.sizes))}, cluster.closed)
+		})
+	}
+}
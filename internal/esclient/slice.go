@@ -0,0 +1,75 @@
+package esclient
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/elastic/go-elasticsearch/v9/typedapi/types"
+	"github.com/fa7ad/esq/internal/options"
+)
+
+// SliceHandler receives each page of search results fetched by a slice. It is
+// called concurrently from different slices.
+type SliceHandler func(slice int, page map[string]any) error
+
+// SearchSliced fetches every document matching the query by splitting a point
+// in time into slices, which are paged through concurrently by at most workers
+// goroutines. The first failing slice cancels the others.
+func (c *esClient) SearchSliced(ctx context.Context, esOpts options.ElasticOptions, slices, workers int, handle SliceHandler) error {
+	body, err := esOpts.SearchRequestBody()
+	if err != nil {
+		return fmt.Errorf("failed to normalize query options: %w", err)
+	}
+
+	pitID, err := c.openPointInTime(ctx, esOpts.Index)
+	if err != nil {
+		return err
+	}
+	defer c.closePointInTime(pitID)
+
+	sliceCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ids := make(chan int)
+	errs := make(chan error, slices)
+	var wg sync.WaitGroup
+	for range min(workers, slices) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range ids {
+				sliceBody := *body
+				if slices > 1 {
+					sliceBody.Slice = &types.SlicedScroll{Id: strconv.Itoa(id), Max: slices}
+				}
+				slicePitID := pitID
+				err := c.pageThrough(sliceCtx, &sliceBody, &slicePitID, esOpts.Size, 0, func(page map[string]any) error {
+					return handle(id, page)
+				})
+				if err != nil {
+					errs <- fmt.Errorf("slice %d: %w", id, err)
+					cancel()
+				}
+			}
+		}()
+	}
+
+feed:
+	for id := range slices {
+		select {
+		case ids <- id:
+		case <-sliceCtx.Done():
+			break feed
+		}
+	}
+	close(ids)
+	wg.Wait()
+	close(errs)
+
+	if err := <-errs; err != nil {
+		return err
+	}
+	return ctx.Err()
+}
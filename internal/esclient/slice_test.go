@@ -0,0 +1,152 @@
+package esclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fa7ad/esq/internal/options"
+)
+
+// sliceCluster is a stub Elasticsearch paging through the slices of a point in
+// time. Each slice has perSlice documents, or never ends if perSlice is 0, and
+// the search of failSlice fails.
+type sliceCluster struct {
+	perSlice  int
+	failSlice string
+
+	mu       sync.Mutex
+	inFlight int
+	// maxInFlight is the largest number of concurrent searches.
+	maxInFlight int
+	closed      []string
+}
+
+func (c *sliceCluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/_pit"):
+		fmt.Fprint(w, `{"id":"pit-0"}`)
+	case r.Method == http.MethodDelete && r.URL.Path == "/_pit":
+		var body struct{ ID string }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		c.mu.Lock()
+		c.closed = append(c.closed, body.ID)
+		c.mu.Unlock()
+		fmt.Fprint(w, `{"succeeded":true}`)
+	case r.URL.Path == "/_search":
+		c.mu.Lock()
+		c.inFlight++
+		c.maxInFlight = max(c.maxInFlight, c.inFlight)
+		c.mu.Unlock()
+		defer func() {
+			c.mu.Lock()
+			c.inFlight--
+			c.mu.Unlock()
+		}()
+		// let concurrent searches overlap
+		time.Sleep(5 * time.Millisecond)
+
+		var body struct {
+			Slice       struct{ ID string }
+			SearchAfter []float64 `json:"search_after"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Slice.ID == c.failSlice {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"error":{"type":"search_phase_execution_exception","reason":"boom"},"status":500}`)
+			return
+		}
+
+		size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+		from := 0
+		if len(body.SearchAfter) > 0 {
+			from = int(body.SearchAfter[0]) + 1
+		}
+		end := from + size
+		if c.perSlice > 0 {
+			end = min(end, c.perSlice)
+		}
+		hits := []any{}
+		for i := from; i < end; i++ {
+			hits = append(hits, map[string]any{"_id": body.Slice.ID + "-" + strconv.Itoa(i), "sort": []any{i}})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"took": 1, "hits": map[string]any{"hits": hits}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"unexpected request"}`)
+	}
+}
+
+func TestSearchSliced(t *testing.T) {
+	testCases := []struct {
+		name      string
+		slices    int
+		workers   int
+		perSlice  int
+		failSlice string
+		wantDocs  int
+		wantErr   string
+	}{
+		{
+			name:     "All Slices",
+			slices:   4,
+			workers:  2,
+			perSlice: 5,
+			wantDocs: 20,
+		},
+		{
+			name:      "Failing Slice",
+			slices:    4,
+			workers:   2,
+			failSlice: "1",
+			wantErr:   "slice 1: search error: [500 Internal Server Error] search_phase_execution_exception: boom",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := &sliceCluster{perSlice: tc.perSlice, failSlice: tc.failSlice}
+			c := newTestClient(t, cluster.ServeHTTP)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			esOpts := options.ElasticOptions{Index: "logs", QueryOptions: options.QueryOptions{DSL: `{"match_all":{}}`, Size: 2}}
+			var mu sync.Mutex
+			seen := map[string]bool{}
+			err := c.SearchSliced(ctx, esOpts, tc.slices, tc.workers, func(slice int, page map[string]any) error {
+				mu.Lock()
+				defer mu.Unlock()
+				for _, hit := range page["hits"].([]any) {
+					id := hit.(map[string]any)["_id"].(string)
+					assert.True(t, strings.HasPrefix(id, strconv.Itoa(slice)+"-"), "hit %s of slice %d", id, slice)
+					assert.False(t, seen[id], "duplicate hit %s", id)
+					seen[id] = true
+				}
+				return nil
+			})
+
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				// the endless slices were cancelled, not timed out
+				assert.NoError(t, ctx.Err())
+			} else {
+				require.NoError(t, err)
+				assert.Len(t, seen, tc.wantDocs)
+			}
+			// cancelled searches may still be served
+			cluster.mu.Lock()
+			defer cluster.mu.Unlock()
+			assert.LessOrEqual(t, cluster.maxInFlight, tc.workers)
+			assert.Equal(t, []string{"pit-0"}, cluster.closed)
+		})
+	}
+}
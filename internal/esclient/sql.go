@@ -0,0 +1,125 @@
+package esclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/fa7ad/esq/internal/options"
+)
+
+// SQL runs the SQL query of esOpts with the _sql API, following its cursor
+// page by page until all rows are fetched, and passes each page to handle.
+// The rows of a page are its hits, like those of a search response, and every
+// page has the columns of the first one. A cursor left open by an error,
+// including one of handle or a cancelled ctx, is cleared.
+func (c *esClient) SQL(ctx context.Context, esOpts options.ElasticOptions, handle PageHandler) error {
+	body, err := esOpts.SQLRequestBody()
+	if err != nil {
+		return err
+	}
+
+	// a cursor left open by a failed page would hold its search context
+	// until it times out, clear it
+	cursor := ""
+	defer func() {
+		if cursor != "" {
+			c.clearSQLCursor(cursor)
+		}
+	}()
+
+	var columns any
+	for {
+		page, err := c.sqlQuery(ctx, body)
+		if err != nil {
+			return err
+		}
+		// only the first page has the columns
+		if columns == nil {
+			columns = page["columns"]
+		} else {
+			page["columns"] = columns
+		}
+
+		// the last page has no cursor, the cluster closes it
+		cursor, _ = page["cursor"].(string)
+		delete(page, "cursor")
+		if err := handle(tabularResponse(page, "rows")); err != nil {
+			return err
+		}
+		if cursor == "" {
+			return nil
+		}
+		if body, err = json.Marshal(map[string]string{"cursor": cursor}); err != nil {
+			return fmt.Errorf("failed to marshal SQL cursor: %w", err)
+		}
+	}
+}
+
+// sqlQuery fetches a page of rows, of a query or a cursor.
+func (c *esClient) sqlQuery(ctx context.Context, body []byte) (map[string]any, error) {
+	res, err := c.client.SQL.Query(
+		bytes.NewReader(body),
+		c.client.SQL.Query.WithContext(ctx),
+		c.client.SQL.Query.WithFormat("json"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch SQL query failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError("SQL query", res)
+	}
+
+	var r map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("failed to parse SQL response body: %w", err)
+	}
+	return r, nil
+}
+
+// clearSQLCursor closes an SQL cursor. Failures are ignored, the cursor
+// expires on its own.
+func (c *esClient) clearSQLCursor(cursor string) {
+	ctx, cancel := context.WithTimeout(context.Background(), pitCloseTimeout)
+	defer cancel()
+
+	body, _ := json.Marshal(map[string]string{"cursor": cursor})
+	res, err := c.client.SQL.ClearCursor(
+		bytes.NewReader(body),
+		c.client.SQL.ClearCursor.WithContext(ctx),
+	)
+	if err == nil {
+		res.Body.Close()
+	}
+}
+
+// SQLTranslate translates the SQL query of esOpts into the body of the search
+// it runs, without running it.
+func (c *esClient) SQLTranslate(ctx context.Context, esOpts options.ElasticOptions) (map[string]any, error) {
+	body, err := esOpts.SQLRequestBody()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.client.SQL.Translate(
+		bytes.NewReader(body),
+		c.client.SQL.Translate.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch SQL translate failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError("SQL translate", res)
+	}
+
+	var r map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("failed to parse SQL translate response body: %w", err)
+	}
+	return r, nil
+}
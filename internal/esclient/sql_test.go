@@ -0,0 +1,161 @@
+package esclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fa7ad/esq/internal/options"
+)
+
+// sqlCluster is a stub Elasticsearch returning the rows of an SQL query in
+// pages, each but the last with a cursor to the next one.
+type sqlCluster struct {
+	rows     int
+	pageSize int
+	// failAt makes the request of that page fail, 1-based
+	failAt int
+
+	mu      sync.Mutex
+	bodies  []map[string]any
+	cleared []string
+}
+
+func (c *sqlCluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var body map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	switch r.URL.Path {
+	case "/_sql/close":
+		cursor, _ := body["cursor"].(string)
+		c.cleared = append(c.cleared, cursor)
+		fmt.Fprint(w, `{"succeeded":true}`)
+	case "/_sql":
+		c.bodies = append(c.bodies, body)
+		page := len(c.bodies)
+		if page == c.failAt {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"error":{"type":"exception","reason":"cursor failed"},"status":500}`)
+			return
+		}
+
+		from := (page - 1) * c.pageSize
+		rows := []any{}
+		for i := from; i < min(from+c.pageSize, c.rows); i++ {
+			rows = append(rows, []any{strconv.Itoa(i), i})
+		}
+		response := map[string]any{"rows": rows}
+		if page == 1 {
+			response["columns"] = []any{
+				map[string]any{"name": "host", "type": "keyword"},
+				map[string]any{"name": "n", "type": "integer"},
+			}
+		}
+		if from+c.pageSize < c.rows {
+			response["cursor"] = fmt.Sprintf("cursor-%d", page)
+		}
+		json.NewEncoder(w).Encode(response)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"unexpected request"}`)
+	}
+}
+
+func TestSQL(t *testing.T) {
+	errHandler := errors.New("handler failed")
+
+	testCases := []struct {
+		name   string
+		rows   int
+		failAt int
+		// handlerFailAt makes the handler of that page fail, 1-based
+		handlerFailAt int
+
+		wantPages   [][]string
+		wantCursors []any
+		wantCleared []string
+		wantErr     error
+	}{
+		{
+			name:        "All Pages",
+			rows:        5,
+			wantPages:   [][]string{{"0", "1"}, {"2", "3"}, {"4"}},
+			wantCursors: []any{nil, "cursor-1", "cursor-2"},
+		},
+		{
+			name:        "Single Page",
+			rows:        2,
+			wantPages:   [][]string{{"0", "1"}},
+			wantCursors: []any{nil},
+		},
+		{
+			name:        "Failing Page",
+			rows:        5,
+			failAt:      2,
+			wantPages:   [][]string{{"0", "1"}},
+			wantCursors: []any{nil, "cursor-1"},
+			wantCleared: []string{"cursor-1"},
+		},
+		{
+			name:          "Handler Error",
+			rows:          5,
+			handlerFailAt: 2,
+			wantPages:     [][]string{{"0", "1"}, {"2", "3"}},
+			wantCursors:   []any{nil, "cursor-1"},
+			wantCleared:   []string{"cursor-2"},
+			wantErr:       errHandler,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := &sqlCluster{rows: tc.rows, pageSize: 2, failAt: tc.failAt}
+			c := newTestClient(t, cluster.ServeHTTP)
+
+			esOpts := options.ElasticOptions{QueryOptions: options.QueryOptions{SQL: "SELECT host, n FROM logs", Size: 2}}
+			var pages [][]string
+			err := c.SQL(context.Background(), esOpts, func(page map[string]any) error {
+				assert.NotContains(t, page, "cursor")
+				assert.Len(t, page["columns"], 2, "every page has the columns")
+				var hosts []string
+				for _, hit := range page["hits"].([]any) {
+					hosts = append(hosts, hit.(map[string]any)["host"].(string))
+				}
+				pages = append(pages, hosts)
+				if len(pages) == tc.handlerFailAt {
+					return errHandler
+				}
+				return nil
+			})
+
+			switch {
+			case tc.wantErr != nil:
+				assert.ErrorIs(t, err, tc.wantErr)
+			case tc.failAt > 0:
+				assert.ErrorContains(t, err, "cursor failed")
+			default:
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantPages, pages)
+
+			// the query, then the cursor of each previous page
+			require.Len(t, cluster.bodies, len(tc.wantCursors))
+			assert.Equal(t, "SELECT host, n FROM logs", cluster.bodies[0]["query"])
+			for i, want := range tc.wantCursors {
+				assert.Equal(t, want, cluster.bodies[i]["cursor"])
+			}
+			assert.Equal(t, tc.wantCleared, cluster.cleared)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package esclient
+
+// tabularResponse converts a tabular response, of ES|QL or SQL, into the
+// envelope of a search response: its rows, under rowsKey, become documents
+// keyed by column name under "hits". The "columns" are kept, in order, for
+// tabular output.
+func tabularResponse(r map[string]any, rowsKey string) map[string]any {
+	columns, _ := r["columns"].([]any)
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		if column, ok := c.(map[string]any); ok {
+			names[i], _ = column["name"].(string)
+		}
+	}
+
+	rows, _ := r[rowsKey].([]any)
+	hits := make([]any, 0, len(rows))
+	for _, row := range rows {
+		values, _ := row.([]any)
+		doc := make(map[string]any, len(names))
+		for i, name := range names {
+			if i < len(values) {
+				doc[name] = values[i]
+			}
+		}
+		hits = append(hits, doc)
+	}
+
+	delete(r, rowsKey)
+	r["hits"] = hits
+	return r
+}
@@ -0,0 +1,28 @@
+package esclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTabularResponse(t *testing.T) {
+	var response map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"took": 5,
+		"columns": [{"name": "service", "type": "keyword"}, {"name": "count()", "type": "long"}],
+		"values": [["api", 12], ["web", null]]
+	}`), &response))
+
+	got := tabularResponse(response, "values")
+
+	assert.Equal(t, []any{
+		map[string]any{"service": "api", "count()": 12.0},
+		map[string]any{"service": "web", "count()": nil},
+	}, got["hits"])
+	assert.Len(t, got["columns"], 2)
+	assert.Equal(t, 5.0, got["took"])
+	assert.NotContains(t, got, "values")
+}
@@ -0,0 +1,192 @@
+package esclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/utils/ptr"
+
+	"github.com/elastic/go-elasticsearch/v9/typedapi/types"
+	"github.com/fa7ad/esq/internal/options"
+)
+
+// tailTimeFormat renders timestamps in sort values and range queries, it is
+// precise enough for both date and date_nanos fields.
+const tailTimeFormat = "strict_date_optional_time_nanos"
+
+// HitsHandler receives a batch of hits in chronological order.
+type HitsHandler func(hits []any) error
+
+// tailCursor tracks the newest document seen so far.
+type tailCursor struct {
+	// sortValues are the sort values of the newest document.
+	sortValues []any
+	// seen holds the ids of the documents sharing the newest timestamp, used
+	// to skip them when no tiebreaker field is configured. It stays empty with
+	// a tiebreaker.
+	seen map[string]bool
+}
+
+// Tail passes the latest esOpts.Size documents matching the query to handle,
+// oldest first by esOpts.TimeField. If tailOpts.Follow is set, it then polls every
+// tailOpts.Interval for newer documents until ctx is cancelled.
+func (c *esClient) Tail(ctx context.Context, esOpts options.ElasticOptions, tailOpts options.TailOptions, handle HitsHandler) error {
+	body, err := esOpts.SearchRequestBody()
+	if err != nil {
+		return fmt.Errorf("failed to normalize query options: %w", err)
+	}
+	baseQuery := body.Query
+
+	// the latest documents, newest first
+	body.Sort = tailSort(esOpts.TimeField, tailOpts.TiebreakerField, "desc")
+	hits, err := c.tailSearch(ctx, esOpts.Index, body, esOpts.Size)
+	if err != nil {
+		return err
+	}
+	for i, j := 0, len(hits)-1; i < j; i, j = i+1, j-1 {
+		hits[i], hits[j] = hits[j], hits[i]
+	}
+
+	cursor := &tailCursor{seen: map[string]bool{}}
+	if err := cursor.emit(hits, handle); err != nil {
+		return err
+	}
+	if !tailOpts.Follow {
+		return nil
+	}
+
+	ticker := time.NewTicker(tailOpts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		// keep fetching while pages are full, to catch up with bursts
+		for {
+			body.Sort = tailSort(esOpts.TimeField, tailOpts.TiebreakerField, "asc")
+			body.Query, body.SearchAfter = cursor.query(baseQuery, esOpts.TimeField, tailOpts.TiebreakerField)
+			// make room for the documents fetched again at the newest timestamp
+			size := esOpts.Size + len(cursor.seen)
+			hits, err := c.tailSearch(ctx, esOpts.Index, body, size)
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := cursor.emit(cursor.unseen(hits), handle); err != nil {
+				return err
+			}
+			if len(hits) < size {
+				break
+			}
+		}
+	}
+}
+
+// tailSort orders documents by time, then by the tiebreaker field if any.
+func tailSort(timeField, tiebreakerField, order string) []types.SortCombinations {
+	sort := []types.SortCombinations{
+		map[string]any{timeField: map[string]any{"order": order, "format": tailTimeFormat}},
+	}
+	if tiebreakerField != "" {
+		sort = append(sort, map[string]any{tiebreakerField: map[string]any{"order": order}})
+	}
+	return sort
+}
+
+// query returns the query for documents newer than the cursor. With a
+// tiebreaker, search_after skips the documents already seen; without one,
+// documents at the newest timestamp are fetched again and skipped by unseen.
+func (t *tailCursor) query(base *types.Query, timeField, tiebreakerField string) (*types.Query, []types.FieldValue) {
+	if len(t.sortValues) == 0 {
+		return base, nil
+	}
+
+	if tiebreakerField != "" {
+		after := make([]types.FieldValue, 0, len(t.sortValues))
+		for _, v := range t.sortValues {
+			after = append(after, v)
+		}
+		return base, after
+	}
+
+	from, _ := json.Marshal(t.sortValues[0])
+	newer := types.Query{Range: map[string]types.RangeQuery{
+		timeField: &types.UntypedRangeQuery{Gte: from, Format: ptr.To(tailTimeFormat)},
+	}}
+	return &types.Query{Bool: &types.BoolQuery{Filter: []types.Query{*base, newer}}}, nil
+}
+
+// unseen drops the documents already passed to the handler.
+func (t *tailCursor) unseen(hits []any) []any {
+	unseen := make([]any, 0, len(hits))
+	for _, hit := range hits {
+		if !t.seen[hitKey(hit)] {
+			unseen = append(unseen, hit)
+		}
+	}
+	return unseen
+}
+
+// emit advances the cursor past the hits and passes them to the handler.
+func (t *tailCursor) emit(hits []any, handle HitsHandler) error {
+	if len(hits) == 0 {
+		return nil
+	}
+	for _, hit := range hits {
+		h, _ := hit.(map[string]any)
+		sortValues, _ := h["sort"].([]any)
+		if len(sortValues) == 0 {
+			continue
+		}
+		if len(t.sortValues) == 0 || sortValues[0] != t.sortValues[0] {
+			t.seen = map[string]bool{}
+		}
+		t.sortValues = sortValues
+		if len(sortValues) == 1 {
+			t.seen[hitKey(hit)] = true
+		}
+	}
+	return handle(hits)
+}
+
+// hitKey identifies a hit across indices.
+func hitKey(hit any) string {
+	h, _ := hit.(map[string]any)
+	return fmt.Sprintf("%v/%v", h["_index"], h["_id"])
+}
+
+// tailSearch runs a single search of the tail and returns its hits.
+func (c *esClient) tailSearch(ctx context.Context, index string, body *types.SearchRequestBody, size int) ([]any, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal query: %w", err)
+	}
+
+	res, err := c.client.Search(
+		c.client.Search.WithContext(ctx),
+		c.client.Search.WithIndex(index),
+		c.client.Search.WithBody(bytes.NewReader(data)),
+		c.client.Search.WithSize(size),
+		c.client.Search.WithTrackTotalHits(false),
+		c.client.Search.WithFilterPath(searchFilterPath...),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch search failed: %w", err)
+	}
+
+	page, err := decodeSearchResponse(res)
+	if err != nil {
+		return nil, err
+	}
+	hits, _ := page["hits"].([]any)
+	return hits, nil
+}
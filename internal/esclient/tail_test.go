@@ -0,0 +1,144 @@
+package esclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v9/typedapi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tailHit returns a hit of index logs with its sort values.
+func tailHit(id string, sortValues ...any) any {
+	hit := map[string]any{"_index": "logs", "_id": id}
+	if len(sortValues) > 0 {
+		hit["sort"] = sortValues
+	}
+	return hit
+}
+
+func TestTailCursor_Emit(t *testing.T) {
+	testCases := []struct {
+		name string
+		// polls are the hits fetched by each poll, the newest timestamp again
+		// included without a tiebreaker
+		polls [][]any
+		want  [][]string
+	}{
+		{
+			name: "Equal Timestamp Boundary",
+			polls: [][]any{
+				{tailHit("a", "t1"), tailHit("b", "t2")},
+				{tailHit("b", "t2"), tailHit("c", "t2")},
+				{tailHit("b", "t2"), tailHit("c", "t2"), tailHit("d", "t3")},
+				{tailHit("d", "t3"), tailHit("e", "t3")},
+			},
+			want: [][]string{{"a", "b"}, {"c"}, {"d"}, {"e"}},
+		},
+		{
+			name: "Duplicates across Polls",
+			polls: [][]any{
+				{tailHit("a", "t1"), tailHit("b", "t1")},
+				{tailHit("a", "t1"), tailHit("b", "t1")},
+				{tailHit("a", "t1"), tailHit("b", "t1")},
+			},
+			want: [][]string{{"a", "b"}, nil, nil},
+		},
+		{
+			name: "Same Id in Another Index",
+			polls: [][]any{
+				{tailHit("a", "t1")},
+				{tailHit("a", "t1"), map[string]any{"_index": "logs-2", "_id": "a", "sort": []any{"t1"}}},
+			},
+			want: [][]string{{"a"}, {"a"}},
+		},
+		{
+			name: "Tiebreaker",
+			polls: [][]any{
+				{tailHit("a", "t1", 1.0), tailHit("b", "t1", 2.0)},
+				// search_after skips the documents seen, none is dropped
+				{tailHit("c", "t1", 3.0)},
+			},
+			want: [][]string{{"a", "b"}, {"c"}},
+		},
+		{
+			// documents missing the tiebreaker field sort last, by the largest long
+			name: "Missing Tiebreaker Value",
+			polls: [][]any{
+				{tailHit("a", "t1", 1.0), tailHit("b", "t1", 9223372036854775807.0)},
+				{tailHit("c", "t2", 1.0)},
+			},
+			want: [][]string{{"a", "b"}, {"c"}},
+		},
+		{
+			name: "Missing Sort Values",
+			polls: [][]any{
+				{tailHit("a", "t1"), tailHit("b")},
+				{tailHit("a", "t1"), tailHit("c", "t2")},
+			},
+			want: [][]string{{"a", "b"}, {"c"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cursor := &tailCursor{seen: map[string]bool{}}
+			var got [][]string
+			for _, hits := range tc.polls {
+				var ids []string
+				err := cursor.emit(cursor.unseen(hits), func(hits []any) error {
+					for _, hit := range hits {
+						ids = append(ids, hit.(map[string]any)["_id"].(string))
+					}
+					return nil
+				})
+				require.NoError(t, err)
+				got = append(got, ids)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestTailCursor_Query(t *testing.T) {
+	base := &types.Query{MatchAll: &types.MatchAllQuery{}}
+
+	testCases := []struct {
+		name            string
+		sortValues      []any
+		tiebreakerField string
+		wantQuery       string
+		wantAfter       []types.FieldValue
+	}{
+		{
+			name:      "Empty Cursor",
+			wantQuery: `{"match_all":{}}`,
+		},
+		{
+			name:            "Tiebreaker",
+			sortValues:      []any{"2025-01-01T00:00:02Z", 7.0},
+			tiebreakerField: "event.sequence",
+			wantQuery:       `{"match_all":{}}`,
+			wantAfter:       []types.FieldValue{"2025-01-01T00:00:02Z", 7.0},
+		},
+		{
+			name:       "Without Tiebreaker",
+			sortValues: []any{"2025-01-01T00:00:02Z"},
+			wantQuery: `{"bool":{"filter":[{"match_all":{}},
+				{"range":{"@timestamp":{"gte":"2025-01-01T00:00:02Z","format":"strict_date_optional_time_nanos"}}}]}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cursor := &tailCursor{sortValues: tc.sortValues, seen: map[string]bool{}}
+			query, after := cursor.query(base, "@timestamp", tc.tiebreakerField)
+
+			data, err := json.Marshal(query)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.wantQuery, string(data))
+			assert.Equal(t, tc.wantAfter, after)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package esclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fa7ad/esq/internal/options"
+)
+
+const (
+	// timeFieldCacheTTL is how long a detected time field is reused.
+	timeFieldCacheTTL  = 24 * time.Hour
+	timeFieldCacheDir  = "esq"
+	timeFieldCacheFile = "time-fields.json"
+)
+
+// preferredTimeFields are picked, in order, when an index has several date fields.
+var preferredTimeFields = []string{"@timestamp", options.DefaultTimeField}
+
+// ResolveTimeField sets esOpts.TimeField, if not configured, to the time field
+// detected from the index mapping. Detected fields are cached per node and
+// index. A dry run uses a cached field, or DefaultTimeField.
+func (c *esClient) ResolveTimeField(ctx context.Context, esOpts *options.ElasticOptions) error {
+	if esOpts.TimeField != "" {
+		return nil
+	}
+
+	key := esOpts.Endpoint() + "|" + esOpts.Index
+	cache := loadTimeFieldCache()
+	if entry, ok := cache[key]; ok && time.Since(entry.DetectedAt) < timeFieldCacheTTL {
+		esOpts.TimeField = entry.Field
+		return nil
+	}
+
+	if c.dryRun {
+		fmt.Fprintf(os.Stderr, "Time field of '%s' not detected in a dry run, using '%s' (set --time-field)\n",
+			esOpts.Index, options.DefaultTimeField)
+		esOpts.TimeField = options.DefaultTimeField
+		return nil
+	}
+
+	field, err := c.DetectTimeField(ctx, esOpts.Index)
+	if err != nil {
+		return err
+	}
+	esOpts.TimeField = field
+
+	cache[key] = timeFieldCacheEntry{Field: field, DetectedAt: time.Now()}
+	cache.save()
+	return nil
+}
+
+// DetectTimeField returns the time field of an index: @timestamp or timestamp
+// if mapped as a date, otherwise its only date field.
+func (c *esClient) DetectTimeField(ctx context.Context, index string) (string, error) {
+	res, err := c.client.FieldCaps(
+		c.client.FieldCaps.WithContext(ctx),
+		c.client.FieldCaps.WithIndex(index),
+		c.client.FieldCaps.WithFields("*"),
+		c.client.FieldCaps.WithTypes("date", "date_nanos"),
+		c.client.FieldCaps.WithFilterPath("fields"),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch field capabilities: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return "", responseError("field capabilities", res)
+	}
+
+	var r struct {
+		Fields map[string]json.RawMessage `json:"fields"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return "", fmt.Errorf("failed to parse field capabilities response body: %w", err)
+	}
+
+	dateFields := make([]string, 0, len(r.Fields))
+	for name := range r.Fields {
+		dateFields = append(dateFields, name)
+	}
+	return pickTimeField(index, dateFields)
+}
+
+// pickTimeField returns the time field among the date fields of an index:
+// the first of preferredTimeFields, or else its only date field.
+func pickTimeField(index string, dateFields []string) (string, error) {
+	dateFields = slices.Sorted(slices.Values(dateFields))
+	for _, preferred := range preferredTimeFields {
+		if slices.Contains(dateFields, preferred) {
+			return preferred, nil
+		}
+	}
+	switch len(dateFields) {
+	case 1:
+		return dateFields[0], nil
+	case 0:
+		return "", fmt.Errorf("no date field found in index '%s', set --time-field", index)
+	default:
+		return "", fmt.Errorf("cannot detect the time field of index '%s' among its date fields %s, set --time-field",
+			index, strings.Join(dateFields, ", "))
+	}
+}
+
+type timeFieldCacheEntry struct {
+	Field      string    `json:"field"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// timeFieldCache maps "endpoint|index" to the time field detected for the index.
+type timeFieldCache map[string]timeFieldCacheEntry
+
+func timeFieldCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, timeFieldCacheDir, timeFieldCacheFile), nil
+}
+
+// loadTimeFieldCache reads the cache, a missing or unreadable cache is empty.
+func loadTimeFieldCache() timeFieldCache {
+	cache := timeFieldCache{}
+	path, err := timeFieldCachePath()
+	if err != nil {
+		return cache
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	_ = json.Unmarshal(data, &cache)
+	return cache
+}
+
+// save writes the cache, failures are ignored as the field is detected again.
+func (t timeFieldCache) save() {
+	path, err := timeFieldCachePath()
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,107 @@
+package esclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fa7ad/esq/internal/options"
+)
+
+func TestPickTimeField(t *testing.T) {
+	testCases := []struct {
+		name       string
+		dateFields []string
+		want       string
+		wantErr    string
+	}{
+		{"@timestamp Preferred", []string{"timestamp", "event.created", "@timestamp"}, "@timestamp", ""},
+		{"timestamp Preferred", []string{"event.created", "timestamp"}, "timestamp", ""},
+		{"Single Date Field", []string{"event.created"}, "event.created", ""},
+		{"No Date Field", nil, "", "no date field found in index 'logs', set --time-field"},
+		{
+			"Several Date Fields", []string{"event.ingested", "event.created"}, "",
+			"cannot detect the time field of index 'logs' among its date fields event.created, event.ingested, set --time-field",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := pickTimeField("logs", tc.dateFields)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestResolveTimeField(t *testing.T) {
+	const key = "http://es:9200|logs"
+
+	testCases := []struct {
+		name      string
+		timeField string
+		cached    *timeFieldCacheEntry
+		want      string
+		// wantDetected is set if the field capabilities were requested
+		wantDetected bool
+	}{
+		{
+			name:      "Configured",
+			timeField: "event.created",
+			want:      "event.created",
+		},
+		{
+			name:   "Cached",
+			cached: &timeFieldCacheEntry{Field: "timestamp", DetectedAt: time.Now().Add(-time.Hour)},
+			want:   "timestamp",
+		},
+		{
+			name:         "Expired",
+			cached:       &timeFieldCacheEntry{Field: "timestamp", DetectedAt: time.Now().Add(-timeFieldCacheTTL - time.Minute)},
+			want:         "@timestamp",
+			wantDetected: true,
+		},
+		{
+			name:         "Not Cached",
+			want:         "@timestamp",
+			wantDetected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("XDG_CACHE_HOME", t.TempDir())
+			t.Setenv("HOME", t.TempDir())
+			if tc.cached != nil {
+				timeFieldCache{key: *tc.cached}.save()
+			}
+
+			detected := false
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				detected = true
+				fmt.Fprint(w, `{"fields":{"@timestamp":{"date":{}},"event.created":{"date":{}}}}`)
+			})
+
+			esOpts := options.ElasticOptions{Nodes: []string{"http://es:9200"}, Index: "logs", QueryOptions: options.QueryOptions{TimeField: tc.timeField}}
+			require.NoError(t, c.ResolveTimeField(context.Background(), &esOpts))
+			assert.Equal(t, tc.want, esOpts.TimeField)
+			assert.Equal(t, tc.wantDetected, detected)
+
+			// detected fields are cached for the next run
+			if tc.wantDetected {
+				entry := loadTimeFieldCache()[key]
+				assert.Equal(t, tc.want, entry.Field)
+				assert.WithinDuration(t, time.Now(), entry.DetectedAt, time.Minute)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package esclient
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fa7ad/esq/internal/options"
+)
+
+// traceLogger logs the round trips of the client, including retries.
+type traceLogger struct {
+	mu sync.Mutex
+	w  io.Writer
+	// bodies logs headers and bodies as well.
+	bodies bool
+	// attempts counts the round trips of each request, which the transport
+	// reuses when retrying, until its last round trip.
+	attempts map[*http.Request]int
+	// maxRetries and retryOnStatus are the retry policy of the transport.
+	maxRetries    int
+	retryOnStatus []int
+}
+
+func newTraceLogger(w io.Writer, verbose int, transportOpts options.TransportOptions) *traceLogger {
+	return &traceLogger{
+		w:             w,
+		bodies:        verbose > 1,
+		attempts:      map[*http.Request]int{},
+		maxRetries:    transportOpts.MaxRetries,
+		retryOnStatus: transportOpts.RetryOnStatus,
+	}
+}
+
+// LogRoundTrip logs a request as "POST http://... 200 OK 37ms 1.2 kB", with
+// its headers and bodies if enabled. Authorization headers are redacted.
+func (l *traceLogger) LogRoundTrip(req *http.Request, res *http.Response, err error, start time.Time, dur time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.attempts[req]++
+	attempt := l.attempts[req]
+	if !l.retried(req, res, err, attempt) {
+		delete(l.attempts, req)
+	}
+
+	// the response body is always passed to the logger, to measure it
+	var body []byte
+	if res != nil && res.Body != nil {
+		body, _ = io.ReadAll(res.Body)
+		res.Body.Close()
+	}
+
+	line := fmt.Sprintf("%s %s", req.Method, req.URL.String())
+	switch {
+	case err != nil:
+		line += fmt.Sprintf(" error: %v %s", err, dur.Round(time.Millisecond))
+	case res != nil:
+		line += fmt.Sprintf(" %s %s %s", res.Status, dur.Round(time.Millisecond), formatBytes(len(body)))
+	}
+	if attempt > 1 {
+		line += fmt.Sprintf(" (retry %d)", attempt-1)
+	}
+	fmt.Fprintln(l.w, line)
+
+	if l.bodies {
+		l.dumpHeaders(">", req.Header)
+		if req.Body != nil && req.Body != http.NoBody {
+			reqBody, _ := io.ReadAll(req.Body)
+			req.Body.Close()
+			l.dumpBody(">", reqBody)
+		}
+		if err == nil && res != nil {
+			l.dumpHeaders("<", res.Header)
+			l.dumpBody("<", body)
+		}
+	}
+	return nil
+}
+
+// retried reports whether the transport retries a round trip, by the retry
+// policy of options.TransportOptions.
+func (l *traceLogger) retried(req *http.Request, res *http.Response, err error, attempt int) bool {
+	if attempt > l.maxRetries {
+		return false
+	}
+	if err != nil {
+		return req.Context().Err() == nil
+	}
+	return res != nil && slices.Contains(l.retryOnStatus, res.StatusCode)
+}
+
+// RequestBodyEnabled makes the transport pass request bodies when they are logged.
+func (l *traceLogger) RequestBodyEnabled() bool { return l.bodies }
+
+// ResponseBodyEnabled makes the transport pass response bodies, always
+// needed to measure the response size.
+func (l *traceLogger) ResponseBodyEnabled() bool { return true }
+
+func (l *traceLogger) dumpHeaders(prefix string, header http.Header) {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		value := strings.Join(header[k], ", ")
+		if k == "Authorization" {
+			value = redactAuthorization(value)
+		}
+		fmt.Fprintf(l.w, "%s %s: %s\n", prefix, k, value)
+	}
+}
+
+func (l *traceLogger) dumpBody(prefix string, body []byte) {
+	scanner := bufio.NewScanner(strings.NewReader(string(body)))
+	scanner.Buffer(nil, len(body)+1)
+	for scanner.Scan() {
+		fmt.Fprintf(l.w, "%s %s\n", prefix, scanner.Text())
+	}
+}
+
+// formatBytes formats a size such as "512 B" or "1.2 kB".
+func formatBytes(n int) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, suffixes := float64(n)/unit, []string{"kB", "MB", "GB"}
+	i := 0
+	for value >= unit && i < len(suffixes)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", value, suffixes[i])
+}
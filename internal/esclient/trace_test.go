@@ -0,0 +1,89 @@
+package esclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fa7ad/esq/internal/options"
+)
+
+func TestTraceLogger(t *testing.T) {
+	newRequest := func() *http.Request {
+		req, err := http.NewRequest(http.MethodPost, "http://localhost:9200/logs/_search?size=1", strings.NewReader(`{"query":{}}`))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "APIKey c2VjcmV0")
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+	newResponse := func(status int, body string) *http.Response {
+		return &http.Response{
+			StatusCode: status,
+			Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+	}
+	start := time.Now()
+
+	retries := options.TransportOptions{MaxRetries: 3, RetryOnStatus: []int{http.StatusServiceUnavailable}}
+
+	t.Run("Summary with retries", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := newTraceLogger(&buf, 1, retries)
+		req := newRequest()
+
+		require.NoError(t, logger.LogRoundTrip(req, nil, errors.New("connection refused"), start, 2*time.Millisecond))
+		require.NoError(t, logger.LogRoundTrip(req, newResponse(503, "busy"), nil, start, 5*time.Millisecond))
+		require.NoError(t, logger.LogRoundTrip(req, newResponse(200, strings.Repeat("x", 1250)), nil, start, 37*time.Millisecond))
+
+		assert.Equal(t,
+			"POST http://localhost:9200/logs/_search?size=1 error: connection refused 2ms\n"+
+				"POST http://localhost:9200/logs/_search?size=1 503 Service Unavailable 5ms 4 B (retry 1)\n"+
+				"POST http://localhost:9200/logs/_search?size=1 200 OK 37ms 1.2 kB (retry 2)\n",
+			buf.String())
+		assert.Empty(t, logger.attempts, "attempts of the final round trip are forgotten")
+	})
+
+	t.Run("Retries exhausted", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := newTraceLogger(&buf, 1, options.TransportOptions{MaxRetries: 1, RetryOnStatus: []int{http.StatusServiceUnavailable}})
+		req := newRequest()
+
+		require.NoError(t, logger.LogRoundTrip(req, newResponse(503, "busy"), nil, start, time.Millisecond))
+		assert.Len(t, logger.attempts, 1)
+		require.NoError(t, logger.LogRoundTrip(req, newResponse(503, "busy"), nil, start, time.Millisecond))
+		assert.Empty(t, logger.attempts)
+
+		// a new round trip of the request is not a retry
+		require.NoError(t, logger.LogRoundTrip(req, newResponse(200, "{}"), nil, start, time.Millisecond))
+		assert.NotContains(t, strings.Split(strings.TrimSpace(buf.String()), "\n")[2], "retry")
+	})
+
+	t.Run("Bodies with redacted authorization", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := newTraceLogger(&buf, 2, retries)
+
+		require.NoError(t, logger.LogRoundTrip(newRequest(), newResponse(200, "{\n  \"took\": 3\n}"), nil, start, time.Millisecond))
+
+		assert.Equal(t,
+			"POST http://localhost:9200/logs/_search?size=1 200 OK 1ms 15 B\n"+
+				"> Authorization: APIKey REDACTED\n"+
+				"> Content-Type: application/json\n"+
+				"> {\"query\":{}}\n"+
+				"< Content-Type: application/json\n"+
+				"< {\n"+
+				"<   \"took\": 3\n"+
+				"< }\n",
+			buf.String())
+		assert.NotContains(t, buf.String(), "c2VjcmV0")
+	})
+}
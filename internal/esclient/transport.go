@@ -0,0 +1,58 @@
+package esclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/fa7ad/esq/internal/options"
+)
+
+// newTransport returns the HTTP transport of the client, with the TLS settings
+// and per-request timeout of opts.
+func newTransport(opts options.ElasticOptions) (http.RoundTripper, error) {
+	tlsConfig, err := opts.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	if opts.Timeout <= 0 {
+		return transport, nil
+	}
+	return &timeoutTransport{next: transport, timeout: opts.Timeout}, nil
+}
+
+// timeoutTransport bounds each request attempt, from sending the request to
+// reading the end of the response body, so that a retry can follow a timeout.
+type timeoutTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	res, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+// cancelOnClose releases the timeout of a request when its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
@@ -0,0 +1,118 @@
+// Package exitcode defines the exit codes of esq, and the typed errors
+// carrying them.
+package exitcode
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+)
+
+// Code is the exit code of esq.
+type Code int
+
+const (
+	// OK is returned when hits were found, or the command succeeded.
+	OK Code = 0
+	// NoHits is returned when nothing matched, with --fail-on-empty.
+	NoHits Code = 1
+	// Usage is returned for invalid flags, configuration or arguments.
+	Usage Code = 2
+	// Auth is returned when Elasticsearch rejects the credentials, or they
+	// cannot be read.
+	Auth Code = 3
+	// Connection is returned when Elasticsearch cannot be reached, or is unavailable.
+	Connection Code = 4
+	// Query is returned when Elasticsearch rejects a request, e.g. a query
+	// that does not parse or a missing index.
+	Query Code = 5
+	// Incomplete is returned when shards failed or a request timed out, and
+	// the results are partial or missing.
+	Incomplete Code = 6
+	// Failure is returned for errors of no other class, so they cannot be
+	// mistaken for NoHits.
+	Failure Code = 7
+	// Interrupted is returned when esq is interrupted, e.g. with Ctrl-C, as
+	// shells report a command killed by SIGINT.
+	Interrupted Code = 130
+)
+
+// Error is an error with an exit code.
+type Error struct {
+	Code Code
+	Err  error
+}
+
+var (
+	// ErrNoHits is returned when nothing matched, with --fail-on-empty.
+	ErrNoHits = &Error{Code: NoHits, Err: errors.New("no hits found")}
+	// ErrPartialResults is returned when shards failed or the search timed
+	// out, after the results were written.
+	ErrPartialResults = &Error{Code: Incomplete, Err: errors.New("results are partial")}
+)
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code of the error.
+func (e *Error) ExitCode() Code {
+	return e.Code
+}
+
+// Wrap returns err with an exit code, or nil if err is nil.
+func Wrap(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Quiet reports whether the error was already reported, e.g. as a warning,
+// or is an interruption, and only sets the exit code.
+func Quiet(err error) bool {
+	return errors.Is(err, ErrNoHits) || errors.Is(err, ErrPartialResults) || errors.Is(err, context.Canceled)
+}
+
+// FromError returns the exit code of an error: Interrupted for a cancelled
+// context, its own if it, or an error it wraps, has an ExitCode method,
+// Incomplete for timeouts, Connection for network and TLS errors, and Failure
+// otherwise.
+func FromError(err error) Code {
+	if err == nil {
+		return OK
+	}
+	// the requests in flight fail with the cancelled context of an interrupt
+	if errors.Is(err, context.Canceled) {
+		return Interrupted
+	}
+
+	var coder interface{ ExitCode() Code }
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+
+	var netErr net.Error
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return Incomplete
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return Incomplete
+	case errors.As(err, &certErr), errors.As(err, &recordErr), errors.As(err, &authorityErr), errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return Connection
+	case errors.As(err, &netErr):
+		return Connection
+	}
+	return Failure
+}
@@ -0,0 +1,69 @@
+package exitcode
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type statusError struct{ code Code }
+
+func (e statusError) Error() string  { return "status error" }
+func (e statusError) ExitCode() Code { return e.code }
+
+func TestFromError(t *testing.T) {
+	refused := &url.Error{Op: "Post", URL: "http://localhost:9200", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}
+
+	testCases := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{"No Error", nil, OK},
+		{"Plain Error", errors.New("boom"), Failure},
+		{"Wrapped Usage Error", fmt.Errorf("invalid flags: %w", Wrap(Usage, errors.New("no index"))), Usage},
+		{"No Hits", ErrNoHits, NoHits},
+		{"Partial Results", ErrPartialResults, Incomplete},
+		{"Error with Exit Code", fmt.Errorf("search failed: %w", statusError{Query}), Query},
+		{"Connection Refused", fmt.Errorf("search failed: %w", refused), Connection},
+		{"Unknown Authority", fmt.Errorf("search failed: %w", &url.Error{Op: "Post", URL: "https://localhost:9200", Err: x509.UnknownAuthorityError{}}), Connection},
+		{"Not TLS", fmt.Errorf("search failed: %w", &url.Error{Op: "Post", URL: "https://localhost:9200", Err: tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}}), Connection},
+		{"Deadline Exceeded", fmt.Errorf("search failed: %w", context.DeadlineExceeded), Incomplete},
+		{"Interrupted", fmt.Errorf("search failed: %w", &url.Error{Op: "Post", URL: "http://localhost:9200", Err: context.Canceled}), Interrupted},
+		{"Interrupted with Exit Code", Wrap(Query, context.Canceled), Interrupted},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, FromError(tc.err))
+		})
+	}
+}
+
+func TestCodes(t *testing.T) {
+	codes := []Code{OK, NoHits, Usage, Auth, Connection, Query, Incomplete, Failure, Interrupted}
+	seen := map[Code]bool{}
+	for _, code := range codes {
+		assert.False(t, seen[code], "code %d is not distinct", code)
+		seen[code] = true
+	}
+}
+
+func TestWrap(t *testing.T) {
+	assert.NoError(t, Wrap(Usage, nil))
+
+	err := errors.New("no index")
+	wrapped := Wrap(Usage, err)
+	assert.ErrorIs(t, wrapped, err)
+	assert.Equal(t, "no index", wrapped.Error())
+	assert.False(t, Quiet(wrapped))
+	assert.True(t, Quiet(fmt.Errorf("search: %w", ErrNoHits)))
+	assert.True(t, Quiet(fmt.Errorf("search: %w", context.Canceled)))
+}
@@ -0,0 +1,75 @@
+package kql
+
+// Node is a node of the KQL abstract syntax tree.
+type Node interface {
+	node()
+}
+
+// MatchAllNode matches every document; it is produced by an empty query.
+type MatchAllNode struct{}
+
+// OrNode matches documents matching at least one of its children.
+type OrNode struct {
+	Children []Node
+}
+
+// AndNode matches documents matching all of its children.
+type AndNode struct {
+	Children []Node
+}
+
+// NotNode matches documents that do not match its child.
+type NotNode struct {
+	Child Node
+}
+
+// NestedNode applies its child query to the nested objects found at Path.
+// Field names inside the child are relative to Path.
+type NestedNode struct {
+	Path  string
+	Child Node
+}
+
+// MatchNode matches a value against a field. An empty Field searches all fields.
+type MatchNode struct {
+	Field string
+	Value Value
+}
+
+// RangeNode compares a field against a value using a range operator.
+type RangeNode struct {
+	Field string
+	Op    RangeOp
+	Value string
+}
+
+// Value is a literal or quoted value of a KQL expression.
+type Value struct {
+	// Text is the unescaped value.
+	Text string
+	// Pattern is the value escaped for use in a query_string query, with
+	// unescaped wildcards preserved.
+	Pattern string
+	// Quoted reports whether the value was a quoted phrase.
+	Quoted bool
+	// Wildcard reports whether the value contains an unescaped '*'.
+	Wildcard bool
+}
+
+// RangeOp is a range comparison operator.
+type RangeOp string
+
+const (
+	OpLT  RangeOp = "lt"
+	OpLTE RangeOp = "lte"
+	OpGT  RangeOp = "gt"
+	OpGTE RangeOp = "gte"
+)
+
+func (MatchAllNode) node() {}
+func (OrNode) node()       {}
+func (AndNode) node()      {}
+func (NotNode) node()      {}
+func (NestedNode) node()   {}
+func (MatchNode) node()    {}
+func (RangeNode) node()    {}
@@ -0,0 +1,152 @@
+package kql
+
+import (
+	"encoding/json"
+	"strings"
+
+	"k8s.io/utils/ptr"
+
+	"github.com/elastic/go-elasticsearch/v9/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v9/typedapi/types/enums/childscoremode"
+	"github.com/elastic/go-elasticsearch/v9/typedapi/types/enums/textquerytype"
+)
+
+// Compile parses a KQL query and translates it into an Elasticsearch query,
+// following the same translation rules as Kibana.
+func Compile(input string) (*types.Query, error) {
+	node, err := Parse(input)
+	if err != nil {
+		return nil, err
+	}
+	query := ToQuery(node)
+	return &query, nil
+}
+
+// ToQuery translates a KQL syntax tree into an Elasticsearch query.
+func ToQuery(node Node) types.Query {
+	return toQuery(node, "")
+}
+
+func toQuery(node Node, nestedPath string) types.Query {
+	switch n := node.(type) {
+	case OrNode:
+		return types.Query{Bool: &types.BoolQuery{
+			Should:             toQueries(n.Children, nestedPath),
+			MinimumShouldMatch: 1,
+		}}
+	case AndNode:
+		return types.Query{Bool: &types.BoolQuery{
+			Filter: toQueries(n.Children, nestedPath),
+		}}
+	case NotNode:
+		return types.Query{Bool: &types.BoolQuery{
+			MustNot: []types.Query{toQuery(n.Child, nestedPath)},
+		}}
+	case NestedNode:
+		path := fieldName(nestedPath, n.Path)
+		return types.Query{Nested: &types.NestedQuery{
+			Path:      path,
+			Query:     toQuery(n.Child, path),
+			ScoreMode: &childscoremode.None,
+		}}
+	case MatchNode:
+		return matchQuery(n, nestedPath)
+	case RangeNode:
+		return rangeQuery(n, nestedPath)
+	default:
+		return types.Query{MatchAll: &types.MatchAllQuery{}}
+	}
+}
+
+func toQueries(nodes []Node, nestedPath string) []types.Query {
+	queries := make([]types.Query, 0, len(nodes))
+	for _, n := range nodes {
+		queries = append(queries, toQuery(n, nestedPath))
+	}
+	return queries
+}
+
+// fieldName resolves a field relative to the enclosing nested path.
+func fieldName(nestedPath, field string) string {
+	if nestedPath == "" {
+		return field
+	}
+	return nestedPath + "." + field
+}
+
+func matchQuery(n MatchNode, nestedPath string) types.Query {
+	// Free text is searched across all fields, or all fields of the nested object.
+	if n.Field == "" {
+		var fields []string
+		if nestedPath != "" {
+			fields = []string{nestedPath + ".*"}
+		}
+		return freeTextQuery(n.Value, fields)
+	}
+
+	field := fieldName(nestedPath, n.Field)
+	switch {
+	case n.Value.Wildcard && n.Value.Text == "*":
+		if strings.Contains(field, "*") {
+			return types.Query{QueryString: &types.QueryStringQuery{Query: "*", Fields: []string{field}}}
+		}
+		return types.Query{Exists: &types.ExistsQuery{Field: field}}
+	case strings.Contains(field, "*"):
+		return freeTextQuery(n.Value, []string{field})
+	case n.Value.Quoted:
+		return types.Query{MatchPhrase: map[string]types.MatchPhraseQuery{
+			field: {Query: n.Value.Text},
+		}}
+	case n.Value.Wildcard:
+		return types.Query{QueryString: &types.QueryStringQuery{
+			Query:           n.Value.Pattern,
+			Fields:          []string{field},
+			AnalyzeWildcard: ptr.To(true),
+		}}
+	default:
+		return types.Query{Match: map[string]types.MatchQuery{
+			field: {Query: n.Value.Text},
+		}}
+	}
+}
+
+// freeTextQuery searches a value across the given field patterns, or all fields if none.
+func freeTextQuery(v Value, fields []string) types.Query {
+	if v.Wildcard {
+		return types.Query{QueryString: &types.QueryStringQuery{
+			Query:           v.Pattern,
+			Fields:          fields,
+			AnalyzeWildcard: ptr.To(true),
+			Lenient:         ptr.To(true),
+		}}
+	}
+	queryType := &textquerytype.Bestfields
+	if v.Quoted {
+		queryType = &textquerytype.Phrase
+	}
+	return types.Query{MultiMatch: &types.MultiMatchQuery{
+		Query:   v.Text,
+		Fields:  fields,
+		Type:    queryType,
+		Lenient: ptr.To(true),
+	}}
+}
+
+func rangeQuery(n RangeNode, nestedPath string) types.Query {
+	value, _ := json.Marshal(n.Value)
+
+	r := types.UntypedRangeQuery{}
+	switch n.Op {
+	case OpLT:
+		r.Lt = value
+	case OpLTE:
+		r.Lte = value
+	case OpGT:
+		r.Gt = value
+	case OpGTE:
+		r.Gte = value
+	}
+	return types.Query{Range: map[string]types.RangeQuery{
+		fieldName(nestedPath, n.Field): &r,
+	}}
+}
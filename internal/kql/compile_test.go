@@ -0,0 +1,79 @@
+package kql
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	testCases := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "Match",
+			query: "user.name: bob",
+			want:  `{"match":{"user.name":{"query":"bob"}}}`,
+		},
+		{
+			name:  "Phrase",
+			query: `message:"disk full"`,
+			want:  `{"match_phrase":{"message":{"query":"disk full"}}}`,
+		},
+		{
+			name:  "Exists",
+			query: "user.email:*",
+			want:  `{"exists":{"field":"user.email"}}`,
+		},
+		{
+			name:  "Wildcard",
+			query: "host:web-*",
+			want:  `{"query_string":{"analyze_wildcard":true,"fields":["host"],"query":"web\\-*"}}`,
+		},
+		{
+			name:  "Free text",
+			query: "timeout",
+			want:  `{"multi_match":{"lenient":true,"query":"timeout","type":"best_fields"}}`,
+		},
+		{
+			name:  "Or",
+			query: "status:(200 or 404)",
+			want:  `{"bool":{"minimum_should_match":1,"should":[{"match":{"status":{"query":"200"}}},{"match":{"status":{"query":"404"}}}]}}`,
+		},
+		{
+			name:  "And not",
+			query: "a:1 and not b:2",
+			want:  `{"bool":{"filter":[{"match":{"a":{"query":"1"}}},{"bool":{"must_not":[{"match":{"b":{"query":"2"}}}]}}]}}`,
+		},
+		{
+			name:  "Range",
+			query: "response < 500",
+			want:  `{"range":{"response":{"lt":"500"}}}`,
+		},
+		{
+			name:  "Nested",
+			query: `items:{ name: "x" and qty > 2 }`,
+			want:  `{"nested":{"path":"items","query":{"bool":{"filter":[{"match_phrase":{"items.name":{"query":"x"}}},{"range":{"items.qty":{"gt":"2"}}}]}},"score_mode":"none"}}`,
+		},
+		{
+			name:  "Empty",
+			query: "",
+			want:  `{"match_all":{}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := Compile(tc.query)
+			require.NoError(t, err)
+
+			got, err := json.Marshal(query)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.want, string(got))
+		})
+	}
+}
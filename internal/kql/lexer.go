@@ -0,0 +1,244 @@
+package kql
+
+import (
+	"strings"
+	"unicode"
+)
+
+// tokenKind identifies the type of a lexical token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenLiteral
+	tokenQuoted
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenColon
+	tokenLParen
+	tokenRParen
+	tokenLBrace
+	tokenRBrace
+	tokenLT
+	tokenLTE
+	tokenGT
+	tokenGTE
+)
+
+// String returns a human readable name of the token kind, used in error messages.
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of query"
+	case tokenLiteral:
+		return "value"
+	case tokenQuoted:
+		return "quoted string"
+	case tokenAnd:
+		return "'and'"
+	case tokenOr:
+		return "'or'"
+	case tokenNot:
+		return "'not'"
+	case tokenColon:
+		return "':'"
+	case tokenLParen:
+		return "'('"
+	case tokenRParen:
+		return "')'"
+	case tokenLBrace:
+		return "'{'"
+	case tokenRBrace:
+		return "'}'"
+	case tokenLT:
+		return "'<'"
+	case tokenLTE:
+		return "'<='"
+	case tokenGT:
+		return "'>'"
+	case tokenGTE:
+		return "'>='"
+	default:
+		return "unknown token"
+	}
+}
+
+// token is a single lexical unit of a KQL query.
+type token struct {
+	kind tokenKind
+	// text is the unescaped value of a literal or quoted string.
+	text string
+	// pattern is the literal rendered as a Lucene pattern: special characters are
+	// escaped, while unescaped '*' wildcards are kept as-is.
+	pattern string
+	// wildcard reports whether the literal contains an unescaped '*'.
+	wildcard bool
+	// pos is the 1-based column of the first character of the token.
+	pos int
+}
+
+// luceneSpecial lists the characters that must be escaped in a query_string query.
+const luceneSpecial = `+-=&|><!(){}[]^"~*?:\/ `
+
+// isSpecial reports whether r terminates an unquoted literal.
+func isSpecial(r rune) bool {
+	return strings.ContainsRune(`\():<>"{}`, r) || unicode.IsSpace(r)
+}
+
+// lex splits the input into tokens.
+func lex(input string) ([]token, error) {
+	runes := []rune(input)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, pos: pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, pos: pos})
+			i++
+		case r == '{':
+			tokens = append(tokens, token{kind: tokenLBrace, pos: pos})
+			i++
+		case r == '}':
+			tokens = append(tokens, token{kind: tokenRBrace, pos: pos})
+			i++
+		case r == ':':
+			tokens = append(tokens, token{kind: tokenColon, pos: pos})
+			i++
+		case r == '<' || r == '>':
+			kind := tokenLT
+			if r == '>' {
+				kind = tokenGT
+			}
+			i++
+			if i < len(runes) && runes[i] == '=' {
+				kind++ // tokenLTE / tokenGTE directly follow tokenLT / tokenGT
+				i++
+			}
+			tokens = append(tokens, token{kind: kind, pos: pos})
+		case r == '"':
+			tok, next, err := lexQuoted(input, runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = next
+		default:
+			tok, next, err := lexLiteral(input, runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = next
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(runes) + 1})
+	return tokens, nil
+}
+
+// lexQuoted reads a double-quoted string starting at runes[start].
+func lexQuoted(input string, runes []rune, start int) (token, int, error) {
+	var sb strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '\\':
+			if i+1 >= len(runes) {
+				return token{}, 0, newSyntaxError(input, i+1, "unfinished escape sequence")
+			}
+			i++
+			sb.WriteRune(unescape(runes[i]))
+		case '"':
+			text := sb.String()
+			return token{kind: tokenQuoted, text: text, pattern: escapeLucene(text), pos: start + 1}, i + 1, nil
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return token{}, 0, newSyntaxError(input, start+1, "unterminated quoted string")
+}
+
+// lexLiteral reads an unquoted literal starting at runes[start].
+func lexLiteral(input string, runes []rune, start int) (token, int, error) {
+	var text, pattern strings.Builder
+	wildcard, escaped := false, false
+
+	i := start
+	for ; i < len(runes); i++ {
+		r := runes[i]
+		if r == '\\' {
+			if i+1 >= len(runes) {
+				return token{}, 0, newSyntaxError(input, i+1, "unfinished escape sequence")
+			}
+			i++
+			escaped = true
+			u := unescape(runes[i])
+			text.WriteRune(u)
+			pattern.WriteString(escapeLucene(string(u)))
+			continue
+		}
+		if isSpecial(r) {
+			break
+		}
+		text.WriteRune(r)
+		if r == '*' {
+			wildcard = true
+			pattern.WriteRune(r)
+		} else {
+			pattern.WriteString(escapeLucene(string(r)))
+		}
+	}
+
+	tok := token{
+		kind:     tokenLiteral,
+		text:     text.String(),
+		pattern:  pattern.String(),
+		wildcard: wildcard,
+		pos:      start + 1,
+	}
+	if !escaped {
+		switch strings.ToLower(tok.text) {
+		case "and":
+			tok.kind = tokenAnd
+		case "or":
+			tok.kind = tokenOr
+		case "not":
+			tok.kind = tokenNot
+		}
+	}
+	return tok, i, nil
+}
+
+// unescape resolves the character following a backslash.
+func unescape(r rune) rune {
+	switch r {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	default:
+		return r
+	}
+}
+
+// escapeLucene escapes all Lucene special characters in s.
+func escapeLucene(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(luceneSpecial, r) {
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
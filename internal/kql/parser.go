@@ -0,0 +1,269 @@
+package kql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntaxError describes a KQL syntax error and the column where it occurred.
+type SyntaxError struct {
+	Query  string
+	Column int
+	Msg    string
+}
+
+func newSyntaxError(query string, column int, format string, args ...any) *SyntaxError {
+	return &SyntaxError{Query: query, Column: column, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Error renders the message followed by the query and a caret under the bad column.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at column %d\n\t%s\n\t%s^", e.Msg, e.Column, e.Query, strings.Repeat(" ", e.Column-1))
+}
+
+// parser is a recursive descent parser over the token stream of a single query.
+type parser struct {
+	input  string
+	tokens []token
+	pos    int
+}
+
+// Parse parses a KQL query into its abstract syntax tree.
+func Parse(input string) (Node, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{input: input, tokens: tokens}
+
+	if p.peek().kind == tokenEOF {
+		return MatchAllNode{}, nil
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.unexpected(tok)
+	}
+	return node, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(kind tokenKind) (token, error) {
+	tok := p.next()
+	if tok.kind != kind {
+		return tok, newSyntaxError(p.input, tok.pos, "expected %s but found %s", kind, describe(tok))
+	}
+	return tok, nil
+}
+
+func (p *parser) unexpected(tok token) error {
+	return newSyntaxError(p.input, tok.pos, "unexpected %s", describe(tok))
+}
+
+// describe renders a token for error messages.
+func describe(tok token) string {
+	switch tok.kind {
+	case tokenLiteral:
+		return fmt.Sprintf("value '%s'", tok.text)
+	case tokenQuoted:
+		return fmt.Sprintf("quoted string \"%s\"", tok.text)
+	default:
+		return tok.kind.String()
+	}
+}
+
+// orExpr := andExpr ( "or" andExpr )*
+func (p *parser) parseOr() (Node, error) {
+	return p.parseBinary(tokenOr, p.parseAnd, func(children []Node) Node { return OrNode{Children: children} })
+}
+
+// andExpr := notExpr ( "and" notExpr )*
+func (p *parser) parseAnd() (Node, error) {
+	return p.parseBinary(tokenAnd, p.parseNot, func(children []Node) Node { return AndNode{Children: children} })
+}
+
+// parseBinary parses a chain of operands joined by the operator token and
+// collapses single-element chains into the operand itself.
+func (p *parser) parseBinary(op tokenKind, operand func() (Node, error), build func([]Node) Node) (Node, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	children := []Node{first}
+	for p.peek().kind == op {
+		p.next()
+		child, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return build(children), nil
+}
+
+// notExpr := "not" notExpr | subQuery
+func (p *parser) parseNot() (Node, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+		child, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return NotNode{Child: child}, nil
+	}
+	return p.parseSubQuery()
+}
+
+// subQuery := "(" orExpr ")" | expression
+func (p *parser) parseSubQuery() (Node, error) {
+	if p.peek().kind != tokenLParen {
+		return p.parseExpression()
+	}
+	p.next()
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokenRParen); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// expression := field ":" "{" orExpr "}"
+//
+//	| field ":" valueExpr
+//	| field rangeOp value
+//	| value
+func (p *parser) parseExpression() (Node, error) {
+	start := p.peek()
+	if start.kind != tokenLiteral && start.kind != tokenQuoted {
+		return nil, p.unexpected(start)
+	}
+
+	value := p.parseValue()
+	switch tok := p.peek(); tok.kind {
+	case tokenColon:
+		p.next()
+		return p.parseFieldExpression(value.Text)
+	case tokenLT, tokenLTE, tokenGT, tokenGTE:
+		p.next()
+		return p.parseRange(value.Text, tok.kind)
+	}
+
+	return MatchNode{Value: value}, nil
+}
+
+// parseFieldExpression parses what follows "field:".
+func (p *parser) parseFieldExpression(field string) (Node, error) {
+	if p.peek().kind == tokenLBrace {
+		p.next()
+		child, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRBrace); err != nil {
+			return nil, err
+		}
+		return NestedNode{Path: field, Child: child}, nil
+	}
+	return p.parseValueExpression(field)
+}
+
+// parseRange parses the value of a range expression.
+func (p *parser) parseRange(field string, op tokenKind) (Node, error) {
+	tok := p.peek()
+	if tok.kind != tokenLiteral && tok.kind != tokenQuoted {
+		return nil, newSyntaxError(p.input, tok.pos, "expected a value after %s but found %s", op, describe(tok))
+	}
+	value := p.parseValue()
+
+	ops := map[tokenKind]RangeOp{tokenLT: OpLT, tokenLTE: OpLTE, tokenGT: OpGT, tokenGTE: OpGTE}
+	return RangeNode{Field: field, Op: ops[op], Value: value.Text}, nil
+}
+
+// valueExpr := "(" valueOr ")" | value
+//
+// Boolean value lists are distributed over the field, so that
+// "status:(200 or 404)" becomes "status:200 or status:404".
+func (p *parser) parseValueExpression(field string) (Node, error) {
+	if p.peek().kind == tokenLParen {
+		p.next()
+		node, err := p.parseValueOr(field)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRParen); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+
+	tok := p.peek()
+	if tok.kind != tokenLiteral && tok.kind != tokenQuoted {
+		return nil, newSyntaxError(p.input, tok.pos, "expected a value for field '%s' but found %s", field, describe(tok))
+	}
+	return MatchNode{Field: field, Value: p.parseValue()}, nil
+}
+
+func (p *parser) parseValueOr(field string) (Node, error) {
+	return p.parseBinary(tokenOr, func() (Node, error) { return p.parseValueAnd(field) },
+		func(children []Node) Node { return OrNode{Children: children} })
+}
+
+func (p *parser) parseValueAnd(field string) (Node, error) {
+	return p.parseBinary(tokenAnd, func() (Node, error) { return p.parseValueNot(field) },
+		func(children []Node) Node { return AndNode{Children: children} })
+}
+
+func (p *parser) parseValueNot(field string) (Node, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+		child, err := p.parseValueNot(field)
+		if err != nil {
+			return nil, err
+		}
+		return NotNode{Child: child}, nil
+	}
+	return p.parseValueExpression(field)
+}
+
+// parseValue consumes a quoted string or a run of unquoted literals. Like
+// Kibana, whitespace-separated words form a single value ("hello world").
+func (p *parser) parseValue() Value {
+	tok := p.next()
+	if tok.kind == tokenQuoted {
+		return Value{Text: tok.text, Pattern: tok.pattern, Quoted: true}
+	}
+
+	texts, patterns := []string{tok.text}, []string{tok.pattern}
+	wildcard := tok.wildcard
+	for p.peek().kind == tokenLiteral {
+		tok = p.next()
+		texts = append(texts, tok.text)
+		patterns = append(patterns, tok.pattern)
+		wildcard = wildcard || tok.wildcard
+	}
+	return Value{
+		Text:     strings.Join(texts, " "),
+		Pattern:  strings.Join(patterns, `\ `),
+		Wildcard: wildcard,
+	}
+}
@@ -0,0 +1,119 @@
+package kql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name  string
+		query string
+		want  Node
+	}{
+		{
+			name:  "Empty query",
+			query: "  ",
+			want:  MatchAllNode{},
+		},
+		{
+			name:  "Field value",
+			query: "user.name: bob",
+			want:  MatchNode{Field: "user.name", Value: Value{Text: "bob", Pattern: "bob"}},
+		},
+		{
+			name:  "Free text with multiple words",
+			query: "hello world",
+			want:  MatchNode{Value: Value{Text: "hello world", Pattern: `hello\ world`}},
+		},
+		{
+			name:  "Quoted phrase",
+			query: `message:"disk full"`,
+			want:  MatchNode{Field: "message", Value: Value{Text: "disk full", Pattern: `disk\ full`, Quoted: true}},
+		},
+		{
+			name:  "Wildcard value",
+			query: "host:web-*",
+			want:  MatchNode{Field: "host", Value: Value{Text: "web-*", Pattern: `web\-*`, Wildcard: true}},
+		},
+		{
+			name:  "Escaped wildcard",
+			query: `name:foo\*`,
+			want:  MatchNode{Field: "name", Value: Value{Text: "foo*", Pattern: `foo\*`}},
+		},
+		{
+			name:  "Value list",
+			query: "status: (200 or 404)",
+			want: OrNode{Children: []Node{
+				MatchNode{Field: "status", Value: Value{Text: "200", Pattern: "200"}},
+				MatchNode{Field: "status", Value: Value{Text: "404", Pattern: "404"}},
+			}},
+		},
+		{
+			name:  "Precedence of and over or",
+			query: "a:1 or b:2 and not c:3",
+			want: OrNode{Children: []Node{
+				MatchNode{Field: "a", Value: Value{Text: "1", Pattern: "1"}},
+				AndNode{Children: []Node{
+					MatchNode{Field: "b", Value: Value{Text: "2", Pattern: "2"}},
+					NotNode{Child: MatchNode{Field: "c", Value: Value{Text: "3", Pattern: "3"}}},
+				}},
+			}},
+		},
+		{
+			name:  "Case insensitive keywords",
+			query: "NOT a:1",
+			want:  NotNode{Child: MatchNode{Field: "a", Value: Value{Text: "1", Pattern: "1"}}},
+		},
+		{
+			name:  "Range",
+			query: "response >= 400",
+			want:  RangeNode{Field: "response", Op: OpGTE, Value: "400"},
+		},
+		{
+			name:  "Nested",
+			query: `items:{ name: "x" }`,
+			want: NestedNode{Path: "items", Child: MatchNode{
+				Field: "name", Value: Value{Text: "x", Pattern: "x", Quoted: true},
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Parse(tc.query)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	testCases := []struct {
+		name       string
+		query      string
+		wantColumn int
+	}{
+		{"Unclosed group", "field: (unclosed", 17},
+		{"Unterminated quote", `msg:"oops`, 5},
+		{"Missing value", "status:", 8},
+		{"Dangling operator", "a:1 and", 8},
+		{"Unexpected closing paren", "a:1)", 4},
+		{"Range without value", "bytes > )", 9},
+		{"Trailing escape", `a:b\`, 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(tc.query)
+			require.Error(t, err)
+
+			var syntaxErr *SyntaxError
+			require.True(t, errors.As(err, &syntaxErr))
+			assert.Equal(t, tc.wantColumn, syntaxErr.Column)
+		})
+	}
+}
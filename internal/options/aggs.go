@@ -0,0 +1,107 @@
+package options
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v9/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v9/typedapi/types/enums/calendarinterval"
+)
+
+// DefaultTermsSize is the number of buckets of a --terms aggregation without a size.
+const DefaultTermsSize = 10
+
+// calendarIntervals are the --date-histogram intervals sent as calendar_interval,
+// all others being sent as fixed_interval.
+var calendarIntervals = []string{
+	"minute", "hour", "day", "week", "month", "quarter", "year",
+	"1m", "1h", "1d", "1w", "1M", "1q", "1y",
+}
+
+// AggregationOptions holds the aggregation shortcuts, each a list of specs.
+type AggregationOptions struct {
+	// Terms specs are "field" or "field:size".
+	Terms []string
+	// DateHistogram specs are "field:interval", e.g. "@timestamp:1h".
+	DateHistogram []string
+
+	Stats       []string
+	Cardinality []string
+	Percentiles []string
+}
+
+// HasAggregations reports whether any aggregation shortcut was provided.
+func (a *AggregationOptions) HasAggregations() bool {
+	return len(a.Terms)+len(a.DateHistogram)+len(a.Stats)+len(a.Cardinality)+len(a.Percentiles) > 0
+}
+
+// Aggregations builds the aggregations of the shortcuts, named after their
+// type and field, e.g. "terms_status" for --terms status.
+func (a *AggregationOptions) Aggregations() (map[string]types.Aggregations, error) {
+	aggs := map[string]types.Aggregations{}
+
+	for _, spec := range a.Terms {
+		field, size, err := parseTermsSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		aggs["terms_"+field] = types.Aggregations{
+			Terms: &types.TermsAggregation{Field: &field, Size: &size},
+		}
+	}
+
+	for _, spec := range a.DateHistogram {
+		field, interval, found := cutLast(spec, ":")
+		if !found || field == "" || interval == "" {
+			return nil, fmt.Errorf("invalid --date-histogram '%s', expected field:interval", spec)
+		}
+		histogram := &types.DateHistogramAggregation{Field: &field}
+		if slices.Contains(calendarIntervals, interval) {
+			histogram.CalendarInterval = &calendarinterval.CalendarInterval{Name: interval}
+		} else {
+			histogram.FixedInterval = interval
+		}
+		aggs["date_histogram_"+field] = types.Aggregations{DateHistogram: histogram}
+	}
+
+	for _, field := range a.Stats {
+		aggs["stats_"+field] = types.Aggregations{Stats: &types.StatsAggregation{Field: &field}}
+	}
+	for _, field := range a.Cardinality {
+		aggs["cardinality_"+field] = types.Aggregations{Cardinality: &types.CardinalityAggregation{Field: &field}}
+	}
+	for _, field := range a.Percentiles {
+		aggs["percentiles_"+field] = types.Aggregations{Percentiles: &types.PercentilesAggregation{Field: &field}}
+	}
+
+	return aggs, nil
+}
+
+// parseTermsSpec splits a "field[:size]" spec.
+func parseTermsSpec(spec string) (string, int, error) {
+	field, sizeText, found := cutLast(spec, ":")
+	if !found {
+		field, sizeText = spec, ""
+	}
+	if field == "" {
+		return "", 0, fmt.Errorf("invalid --terms '%s', expected field[:size]", spec)
+	}
+	if sizeText == "" {
+		return field, DefaultTermsSize, nil
+	}
+	size, err := strconv.Atoi(sizeText)
+	if err != nil || size <= 0 {
+		return "", 0, fmt.Errorf("invalid --terms '%s', size must be a positive number", spec)
+	}
+	return field, size, nil
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
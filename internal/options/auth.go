@@ -1,17 +1,40 @@
 package options
 
-import "github.com/elastic/go-elasticsearch/v9"
+import (
+	"net/http"
+
+	"github.com/elastic/go-elasticsearch/v9"
+)
 
 // AuthOptions holds authentication-related fields.
 type AuthOptions struct {
 	APIKey   string
 	Username string
 	Password string
+
+	// BearerToken is an OAuth2/OIDC access token, sent as "Authorization: Bearer".
+	BearerToken string
+	// ServiceToken is the token of an Elasticsearch service account.
+	ServiceToken string
+
+	// PasswordStdin, PasswordFile, APIKeyFile and CredentialCommand are
+	// sources of secrets, read by ResolveCredentials.
+	PasswordStdin     bool
+	PasswordFile      string
+	APIKeyFile        string
+	CredentialCommand string
 }
 
 func (a *AuthOptions) UpdateConfig(receiver *elasticsearch.Config) {
 	if a.APIKey != "" {
 		receiver.APIKey = a.APIKey
+	} else if a.ServiceToken != "" {
+		receiver.ServiceToken = a.ServiceToken
+	} else if a.BearerToken != "" {
+		if receiver.Header == nil {
+			receiver.Header = http.Header{}
+		}
+		receiver.Header.Set("Authorization", "Bearer "+a.BearerToken)
 	} else if a.Username != "" && a.Password != "" {
 		receiver.Username = a.Username
 		receiver.Password = a.Password
@@ -14,6 +14,8 @@ func TestAuthOptions_UpdateConfig(t *testing.T) {
 		wantAPIKey   string
 		wantUsername string
 		wantPassword string
+		wantToken    string
+		wantHeader   string
 	}{
 		{
 			name:       "API Key Auth",
@@ -26,6 +28,16 @@ func TestAuthOptions_UpdateConfig(t *testing.T) {
 			wantUsername: "user",
 			wantPassword: "pw",
 		},
+		{
+			name:      "Service Token Auth",
+			opts:      AuthOptions{ServiceToken: "AAEAAWVsYXN0aWM"},
+			wantToken: "AAEAAWVsYXN0aWM",
+		},
+		{
+			name:       "Bearer Token Auth",
+			opts:       AuthOptions{BearerToken: "eyJhbGciOi"},
+			wantHeader: "Bearer eyJhbGciOi",
+		},
 		{
 			name: "No Auth",
 			opts: AuthOptions{},
@@ -40,6 +52,8 @@ func TestAuthOptions_UpdateConfig(t *testing.T) {
 			assert.Equal(t, tc.wantAPIKey, cfg.APIKey)
 			assert.Equal(t, tc.wantUsername, cfg.Username)
 			assert.Equal(t, tc.wantPassword, cfg.Password)
+			assert.Equal(t, tc.wantToken, cfg.ServiceToken)
+			assert.Equal(t, tc.wantHeader, cfg.Header.Get("Authorization"))
 		})
 	}
 }
@@ -0,0 +1,120 @@
+package options
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ResolveCredentials reads the password or API key from their configured
+// source: stdin with PasswordStdin, PasswordFile, APIKeyFile, or the output of
+// CredentialCommand, which is the password if Username is set and the API key
+// otherwise. If Username is set without a password and stdin is a terminal,
+// the password is prompted for without echo.
+func (a *AuthOptions) ResolveCredentials(stdin io.Reader) error {
+	switch {
+	case a.PasswordStdin:
+		line, err := bufio.NewReader(stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("error reading password from stdin: %w", err)
+		}
+		a.Password = strings.TrimRight(line, "\r\n")
+	case a.PasswordFile != "":
+		password, err := readSecretFile(a.PasswordFile)
+		if err != nil {
+			return err
+		}
+		a.Password = password
+	}
+
+	if a.APIKeyFile != "" {
+		apiKey, err := readSecretFile(a.APIKeyFile)
+		if err != nil {
+			return err
+		}
+		a.APIKey = apiKey
+	}
+
+	if a.CredentialCommand != "" {
+		secret, err := runCredentialCommand(a.CredentialCommand)
+		if err != nil {
+			return err
+		}
+		if a.Username != "" {
+			a.Password = secret
+		} else {
+			a.APIKey = secret
+		}
+	}
+
+	if a.Username != "" && a.Password == "" {
+		if f, ok := stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+			fmt.Fprintf(os.Stderr, "Password for %s: ", a.Username)
+			password, err := term.ReadPassword(int(f.Fd()))
+			fmt.Fprintln(os.Stderr)
+			if err != nil {
+				return fmt.Errorf("error reading password: %w", err)
+			}
+			a.Password = string(password)
+		}
+	}
+
+	return nil
+}
+
+// credentialPlaceholder stands in for the secrets that are not resolved in a
+// dry run.
+const credentialPlaceholder = "REDACTED"
+
+// PlaceholderCredentials sets a placeholder for the password or API key to be
+// read from a secret source, run by CredentialCommand or prompted for, without
+// resolving it. Requests printed by a dry run, with their secrets redacted,
+// then still show how they authenticate.
+func (a *AuthOptions) PlaceholderCredentials() {
+	if a.APIKeyFile != "" || (a.CredentialCommand != "" && a.Username == "") {
+		a.APIKey = credentialPlaceholder
+	}
+	if a.Username != "" && a.Password == "" {
+		a.Password = credentialPlaceholder
+	}
+}
+
+// readSecretFile reads a secret from a file, without surrounding whitespace.
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading secret file '%s': %w", path, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// runCredentialCommand runs a credential helper, such as "pass show es/prod",
+// through the shell and returns the first line of its output. The helper can
+// prompt on the terminal through stdin and stderr.
+func runCredentialCommand(command string) (string, error) {
+	cmd := exec.Command("sh", "-c", command)
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	}
+	var stdout bytes.Buffer
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("credential command failed: %w", err)
+	}
+
+	secret, _, _ := strings.Cut(stdout.String(), "\n")
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return "", fmt.Errorf("credential command printed no secret")
+	}
+	return secret, nil
+}
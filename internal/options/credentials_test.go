@@ -0,0 +1,131 @@
+package options
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthOptions_ResolveCredentials(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("s3cret\n"), 0600))
+	apiKeyFile := filepath.Join(dir, "api-key")
+	require.NoError(t, os.WriteFile(apiKeyFile, []byte("  a2V5\n"), 0600))
+
+	testCases := []struct {
+		name         string
+		opts         AuthOptions
+		stdin        string
+		wantPassword string
+		wantAPIKey   string
+		wantErr      bool
+	}{
+		{
+			name:         "Password from stdin",
+			opts:         AuthOptions{Username: "elastic", PasswordStdin: true},
+			stdin:        "from-stdin\nignored\n",
+			wantPassword: "from-stdin",
+		},
+		{
+			name:         "Password file",
+			opts:         AuthOptions{Username: "elastic", PasswordFile: passwordFile},
+			wantPassword: "s3cret",
+		},
+		{
+			name:       "API key file",
+			opts:       AuthOptions{APIKeyFile: apiKeyFile},
+			wantAPIKey: "a2V5",
+		},
+		{
+			name:         "Credential command for a password",
+			opts:         AuthOptions{Username: "elastic", CredentialCommand: "echo from-helper; echo metadata"},
+			wantPassword: "from-helper",
+		},
+		{
+			name:       "Credential command for an API key",
+			opts:       AuthOptions{CredentialCommand: "printf a2V5"},
+			wantAPIKey: "a2V5",
+		},
+		{
+			name:    "Failing credential command",
+			opts:    AuthOptions{CredentialCommand: "exit 1"},
+			wantErr: true,
+		},
+		{
+			name:    "Missing password file",
+			opts:    AuthOptions{Username: "elastic", PasswordFile: filepath.Join(dir, "missing")},
+			wantErr: true,
+		},
+		{
+			name:         "No prompt without terminal",
+			opts:         AuthOptions{Username: "elastic"},
+			wantPassword: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.opts.ResolveCredentials(strings.NewReader(tc.stdin))
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantPassword, tc.opts.Password)
+			assert.Equal(t, tc.wantAPIKey, tc.opts.APIKey)
+		})
+	}
+}
+
+func TestAuthOptions_PlaceholderCredentials(t *testing.T) {
+	testCases := []struct {
+		name         string
+		opts         AuthOptions
+		wantPassword string
+		wantAPIKey   string
+	}{
+		{
+			name:         "Password from stdin",
+			opts:         AuthOptions{Username: "elastic", PasswordStdin: true},
+			wantPassword: credentialPlaceholder,
+		},
+		{
+			name:         "Credential command for a password",
+			opts:         AuthOptions{Username: "elastic", CredentialCommand: "exit 1"},
+			wantPassword: credentialPlaceholder,
+		},
+		{
+			name:       "Credential command for an API key",
+			opts:       AuthOptions{CredentialCommand: "exit 1"},
+			wantAPIKey: credentialPlaceholder,
+		},
+		{
+			name:       "API key file",
+			opts:       AuthOptions{APIKeyFile: "missing"},
+			wantAPIKey: credentialPlaceholder,
+		},
+		{
+			name:         "Password prompt",
+			opts:         AuthOptions{Username: "elastic"},
+			wantPassword: credentialPlaceholder,
+		},
+		{
+			name:         "Given password",
+			opts:         AuthOptions{Username: "elastic", Password: "pw"},
+			wantPassword: "pw",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.opts.PlaceholderCredentials()
+			assert.Equal(t, tc.wantPassword, tc.opts.Password)
+			assert.Equal(t, tc.wantAPIKey, tc.opts.APIKey)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package options
+
+// DryRunOptions print the requests to Elasticsearch instead of sending them.
+type DryRunOptions struct {
+	DryRun bool
+	// PrintCurl prints the requests as curl commands, and implies DryRun.
+	PrintCurl bool
+}
@@ -1,8 +1,24 @@
 package options
 
+import "strings"
+
 type ElasticOptions struct {
-	Node  string
-	Index string
+	Nodes []string
+	// CloudID is the Elastic Cloud deployment to connect to, instead of Nodes.
+	CloudID string
+	Index   string
 
+	TLSOptions
+	TransportOptions
+	DryRunOptions
+	TraceOptions
 	QueryOptions
 }
+
+// Endpoint identifies the cluster connected to: its cloud ID, or its nodes.
+func (e *ElasticOptions) Endpoint() string {
+	if e.CloudID != "" {
+		return e.CloudID
+	}
+	return strings.Join(e.Nodes, ",")
+}
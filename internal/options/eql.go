@@ -0,0 +1,65 @@
+package options
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v9/typedapi/types"
+)
+
+// EQLOptions configure the fields an EQL query relies on.
+type EQLOptions struct {
+	// TimestampField orders events, --time-field or @timestamp if empty.
+	TimestampField string
+	// EventCategoryField holds the event categories, event.category if empty.
+	EventCategoryField string
+	// TiebreakerField orders events with the same timestamp.
+	TiebreakerField string
+}
+
+// HasEQLOptions reports whether any EQL option was provided.
+func (e *EQLOptions) HasEQLOptions() bool {
+	return e.TimestampField != "" || e.EventCategoryField != "" || e.TiebreakerField != ""
+}
+
+// eqlRequest is the body of an EQL search request.
+type eqlRequest struct {
+	Query              string                 `json:"query"`
+	Size               int                    `json:"size"`
+	TimestampField     string                 `json:"timestamp_field,omitempty"`
+	EventCategoryField string                 `json:"event_category_field,omitempty"`
+	TiebreakerField    string                 `json:"tiebreaker_field,omitempty"`
+	Fields             []types.FieldAndFormat `json:"fields,omitempty"`
+	Filter             *types.Query           `json:"filter,omitempty"`
+}
+
+// EQLRequestBody builds the body of the --eql query, with --from, --to and
+// the filter flags as its DSL filter.
+func (q *QueryOptions) EQLRequestBody() ([]byte, error) {
+	req := eqlRequest{
+		Query:              q.EQL,
+		Size:               q.Size,
+		TimestampField:     q.TimestampField,
+		EventCategoryField: q.EventCategoryField,
+		TiebreakerField:    q.TiebreakerField,
+		Fields:             q.FieldFormats(),
+	}
+	if req.TimestampField == "" {
+		req.TimestampField = q.TimeField
+	}
+
+	// the time range applies to the timestamp field of the events
+	filterOpts := *q
+	filterOpts.TimeField = req.TimestampField
+	filter, err := filterOpts.FilterQuery()
+	if err != nil {
+		return nil, err
+	}
+	req.Filter = filter
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal EQL query: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,50 @@
+package options
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryOptions_EQLRequestBody(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts QueryOptions
+		want string
+	}{
+		{
+			name: "Query only",
+			opts: QueryOptions{EQL: "process where process.name == \"curl\"", Size: 10},
+			want: `{"query":"process where process.name == \"curl\"","size":10}`,
+		},
+		{
+			name: "Sequence with fields and time range",
+			opts: QueryOptions{
+				EQL:          "sequence by host.id [process where true] [network where true]",
+				Size:         100,
+				From:         "now-1d",
+				TimeField:    "timestamp",
+				EQLOptions:   EQLOptions{TimestampField: "event.created", EventCategoryField: "event.type", TiebreakerField: "event.sequence"},
+				FieldOptions: FieldOptions{FetchFields: []string{"_id", "process.name"}},
+			},
+			want: `{"query":"sequence by host.id [process where true] [network where true]","size":100,
+				"timestamp_field":"event.created","event_category_field":"event.type","tiebreaker_field":"event.sequence",
+				"fields":[{"field":"process.name","include_unmapped":true}],
+				"filter":{"bool":{"filter":[{"range":{"event.created":{"gte":"now-1d"}}}]}}}`,
+		},
+		{
+			name: "Time field as timestamp field",
+			opts: QueryOptions{EQL: "any where true", Size: 10, To: "now", TimeField: "@timestamp"},
+			want: `{"query":"any where true","size":10,"timestamp_field":"@timestamp","filter":{"bool":{"filter":[{"range":{"@timestamp":{"lte":"now"}}}]}}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.opts.EQLRequestBody()
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.want, string(got))
+		})
+	}
+}
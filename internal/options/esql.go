@@ -0,0 +1,73 @@
+package options
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v9/typedapi/types"
+)
+
+// esqlRequest is the body of an ES|QL query request.
+type esqlRequest struct {
+	Query  string       `json:"query"`
+	Params []any        `json:"params,omitempty"`
+	Filter *types.Query `json:"filter,omitempty"`
+}
+
+// ESQLRequestBody builds the body of the --esql query, with its positional
+// parameters, and --from, --to and the filter flags as its DSL filter.
+func (q *QueryOptions) ESQLRequestBody() ([]byte, error) {
+	req := esqlRequest{Query: q.ESQL, Params: ParseParams(q.ESQLParams)}
+
+	filter, err := q.FilterQuery()
+	if err != nil {
+		return nil, err
+	}
+	req.Filter = filter
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ES|QL query: %w", err)
+	}
+	return data, nil
+}
+
+// FilterQuery combines --from, --to and the filter flags into a bool query
+// with no scoring clauses, or returns nil if none is set. It filters query
+// languages that take a DSL filter alongside their own query.
+func (q *QueryOptions) FilterQuery() (*types.Query, error) {
+	filter, mustNot, err := q.FilterClauses()
+	if err != nil {
+		return nil, err
+	}
+	if tsQuery := q.timeRangeQuery(); tsQuery != nil {
+		filter = append([]types.Query{*tsQuery}, filter...)
+	}
+	if len(filter) == 0 && len(mustNot) == 0 {
+		return nil, nil
+	}
+	return &types.Query{Bool: &types.BoolQuery{Filter: filter, MustNot: mustNot}}, nil
+}
+
+// ParseParams converts query parameters into JSON values: numbers, booleans,
+// null and quoted strings as such, e.g. "42" as a number and "\"42\"" as a
+// string, and any other value as a string.
+func ParseParams(params []string) []any {
+	values := make([]any, 0, len(params))
+	for _, p := range params {
+		var v any
+		if err := json.Unmarshal([]byte(p), &v); err != nil || !isScalar(v) {
+			v = p
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+func isScalar(v any) bool {
+	switch v.(type) {
+	case nil, bool, float64, string:
+		return true
+	}
+	return false
+}
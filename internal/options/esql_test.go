@@ -0,0 +1,45 @@
+package options
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryOptions_ESQLRequestBody(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts QueryOptions
+		want string
+	}{
+		{
+			name: "Query only",
+			opts: QueryOptions{ESQL: "FROM logs | LIMIT 10"},
+			want: `{"query":"FROM logs | LIMIT 10"}`,
+		},
+		{
+			name: "Typed parameters",
+			opts: QueryOptions{ESQL: "FROM logs | WHERE a == ? AND b > ? AND c == ? AND d == ?", ESQLParams: []string{"error", "42", "true", `"42"`}},
+			want: `{"query":"FROM logs | WHERE a == ? AND b > ? AND c == ? AND d == ?","params":["error",42,true,"42"]}`,
+		},
+		{
+			name: "Time range and filters",
+			opts: QueryOptions{
+				ESQL:          "FROM logs",
+				From:          "now-1h",
+				TimeField:     "@timestamp",
+				FilterOptions: FilterOptions{Filter: []string{"env=prod"}, Missing: []string{"user.id"}},
+			},
+			want: `{"query":"FROM logs","filter":{"bool":{"filter":[{"range":{"@timestamp":{"gte":"now-1h"}}},{"match_phrase":{"env":{"query":"prod"}}}],"must_not":[{"exists":{"field":"user.id"}}]}}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.opts.ESQLRequestBody()
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package options
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ExportOptions holds fields of the export command.
+type ExportOptions struct {
+	Slices   int
+	Workers  int
+	PerSlice bool
+}
+
+// SliceFile returns the output file of a slice when writing one file per slice,
+// e.g. "export.ndjson" becomes "export-3.ndjson" for slice 3.
+func (e *ExportOptions) SliceFile(outputFile string, slice int) string {
+	ext := filepath.Ext(outputFile)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(outputFile, ext), slice, ext)
+}
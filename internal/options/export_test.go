@@ -0,0 +1,27 @@
+package options
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportOptions_SliceFile(t *testing.T) {
+	testCases := []struct {
+		name       string
+		outputFile string
+		slice      int
+		want       string
+	}{
+		{"With Extension", "export.ndjson", 3, "export-3.ndjson"},
+		{"With Directory", "/tmp/out/data.json", 0, "/tmp/out/data-0.json"},
+		{"Without Extension", "export", 1, "export-1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := ExportOptions{}
+			assert.Equal(t, tc.want, opts.SliceFile(tc.outputFile, tc.slice))
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package options
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/utils/ptr"
+
+	"github.com/elastic/go-elasticsearch/v9/typedapi/types"
+)
+
+// FieldOptions select the fields returned for each hit, and the order of hits.
+type FieldOptions struct {
+	// SourceIncludes and SourceExcludes filter the _source of hits, with
+	// wildcard patterns.
+	SourceIncludes []string
+	SourceExcludes []string
+	// FetchFields are fetched with the fields API, which also returns runtime
+	// and unmapped fields, instead of the _source unless it is filtered. They
+	// are the columns of the output --fields.
+	FetchFields []string
+	// DocvalueFields are fetched from doc values.
+	DocvalueFields []string
+	// Sort specs are "field" or "field:asc" or "field:desc".
+	Sort []string
+}
+
+// UpdateBody merges the field options into a search request body: source
+// filters and sort replace those of the body, fields are added to its own.
+// Without source filters, fetched fields disable the _source of a body that
+// does not set it, so that their values are only returned by the fields API.
+func (f *FieldOptions) UpdateBody(body *types.SearchRequestBody) error {
+	switch {
+	case len(f.SourceIncludes) > 0 || len(f.SourceExcludes) > 0:
+		body.Source_ = types.SourceFilter{Includes: f.SourceIncludes, Excludes: f.SourceExcludes}
+	case len(f.FetchFields) > 0 && body.Source_ == nil:
+		body.Source_ = false
+	}
+
+	body.Fields = append(body.Fields, f.FieldFormats()...)
+	for _, field := range f.DocvalueFields {
+		body.DocvalueFields = append(body.DocvalueFields, types.FieldAndFormat{Field: field})
+	}
+
+	if len(f.Sort) > 0 {
+		sort, err := f.SortCombinations()
+		if err != nil {
+			return err
+		}
+		body.Sort = sort
+	}
+	return nil
+}
+
+// FieldFormats returns the fields to request with the fields API, including
+// unmapped fields.
+func (f *FieldOptions) FieldFormats() []types.FieldAndFormat {
+	var fields []types.FieldAndFormat
+	for _, field := range f.FetchFields {
+		// metadata such as _id is part of every hit
+		if strings.HasPrefix(field, "_") {
+			continue
+		}
+		fields = append(fields, types.FieldAndFormat{Field: field, IncludeUnmapped: ptr.To(true)})
+	}
+	return fields
+}
+
+// SortCombinations builds the sort of the --sort specs.
+func (f *FieldOptions) SortCombinations() ([]types.SortCombinations, error) {
+	sort := make([]types.SortCombinations, 0, len(f.Sort))
+	for _, spec := range f.Sort {
+		field, order, err := parseSortSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		sort = append(sort, map[string]any{field: map[string]any{"order": order}})
+	}
+	return sort, nil
+}
+
+// parseSortSpec parses a --sort spec, "field" or "field:order", the order
+// being asc or desc and defaulting to asc.
+func parseSortSpec(spec string) (string, string, error) {
+	field, order, found := cutLast(spec, ":")
+	if !found {
+		order = "asc"
+	}
+	if field == "" || (order != "asc" && order != "desc") {
+		return "", "", fmt.Errorf("invalid --sort '%s', expected field[:asc|desc]", spec)
+	}
+	return field, order, nil
+}
@@ -0,0 +1,111 @@
+package options
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v9/typedapi/types"
+)
+
+// rangeOperators are the operators of --range specs, longest first.
+var rangeOperators = []string{">=", "<=", ">", "<"}
+
+// FilterOptions hold filters narrowing any query, without affecting scoring.
+type FilterOptions struct {
+	// Filter and Exclude specs are "field=value", matched as a phrase.
+	Filter  []string
+	Exclude []string
+	// Exists and Missing are fields that must have, or must not have, a value.
+	Exists  []string
+	Missing []string
+	// Range specs are "field>=value", with one of the >=, <=, > or < operators.
+	Range []string
+}
+
+// HasFilters reports whether any filter was provided.
+func (f *FilterOptions) HasFilters() bool {
+	return len(f.Filter)+len(f.Exclude)+len(f.Exists)+len(f.Missing)+len(f.Range) > 0
+}
+
+// FilterClauses compiles the filters into the bool.filter and bool.must_not
+// clauses wrapped around the query.
+func (f *FilterOptions) FilterClauses() (filter, mustNot []types.Query, err error) {
+	for _, spec := range f.Filter {
+		q, err := phraseClause("--filter", spec)
+		if err != nil {
+			return nil, nil, err
+		}
+		filter = append(filter, q)
+	}
+	for _, spec := range f.Range {
+		q, err := rangeClause(spec)
+		if err != nil {
+			return nil, nil, err
+		}
+		filter = append(filter, q)
+	}
+	for _, field := range f.Exists {
+		filter = append(filter, types.Query{Exists: &types.ExistsQuery{Field: field}})
+	}
+
+	for _, spec := range f.Exclude {
+		q, err := phraseClause("--exclude", spec)
+		if err != nil {
+			return nil, nil, err
+		}
+		mustNot = append(mustNot, q)
+	}
+	for _, field := range f.Missing {
+		mustNot = append(mustNot, types.Query{Exists: &types.ExistsQuery{Field: field}})
+	}
+	return filter, mustNot, nil
+}
+
+// phraseClause compiles a "field=value" spec of flag into a match_phrase
+// query, which matches keyword and text fields alike.
+func phraseClause(flag, spec string) (types.Query, error) {
+	field, value, found := strings.Cut(spec, "=")
+	field = strings.TrimSpace(field)
+	if !found || field == "" {
+		return types.Query{}, fmt.Errorf("invalid %s '%s', expected field=value", flag, spec)
+	}
+	return types.Query{MatchPhrase: map[string]types.MatchPhraseQuery{
+		field: {Query: value},
+	}}, nil
+}
+
+// rangeClause compiles a --range spec such as "bytes>=1024" into a range query.
+func rangeClause(spec string) (types.Query, error) {
+	i := strings.IndexAny(spec, "<>")
+	if i <= 0 {
+		return types.Query{}, fmt.Errorf("invalid --range '%s', expected field followed by >=, <=, > or < and a value", spec)
+	}
+	field, rest := strings.TrimSpace(spec[:i]), spec[i:]
+
+	var op string
+	for _, o := range rangeOperators {
+		if strings.HasPrefix(rest, o) {
+			op = o
+			break
+		}
+	}
+	value := strings.TrimSpace(strings.TrimPrefix(rest, op))
+	if field == "" || value == "" {
+		return types.Query{}, fmt.Errorf("invalid --range '%s', expected field followed by >=, <=, > or < and a value", spec)
+	}
+	raw, _ := json.Marshal(value)
+
+	r := types.UntypedRangeQuery{}
+	switch op {
+	case ">=":
+		r.Gte = raw
+	case "<=":
+		r.Lte = raw
+	case ">":
+		r.Gt = raw
+	case "<":
+		r.Lt = raw
+	}
+	return types.Query{Range: map[string]types.RangeQuery{field: &r}}, nil
+}
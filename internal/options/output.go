@@ -2,6 +2,8 @@ package options
 
 import (
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/fa7ad/esq/internal/output"
 )
@@ -11,6 +13,25 @@ type OutputOptions struct {
 	Output     string
 	OutputFile string
 	JqPath     string
+	FullHit    bool
+
+	// Fields, NoHeader and ArrayDelimiter configure the tabular formats.
+	Fields         []string
+	NoHeader       bool
+	ArrayDelimiter string
+
+	// Template or TemplateFile hold the text/template of the template format,
+	// executed per hit, or per response with TemplateResponse.
+	Template         string
+	TemplateFile     string
+	TemplateResponse bool
+}
+
+// ResultWriter writes one or more result sets to the configured output.
+type ResultWriter struct {
+	opts      *OutputOptions
+	w         io.WriteCloser
+	formatter output.Formatter
 }
 
 // processResults applies the jq expression to the results if specified.
@@ -25,22 +46,152 @@ func (o *OutputOptions) processResults(results any) (any, error) {
 	return parsed, nil
 }
 
-// OutputResults processes and outputs the results to the specified format and file.
-func (o *OutputOptions) OutputResults(results any) error {
-	processed, err := o.processResults(results)
-	if err != nil {
-		return err
+// SetDefaultFormat picks the output format if none was specified: an aligned
+// table for interactive use, text otherwise.
+func (o *OutputOptions) SetDefaultFormat() {
+	if o.Output != "" {
+		return
+	}
+	if o.Template != "" || o.TemplateFile != "" {
+		o.Output = "template"
+		return
 	}
+	o.Output = "text"
+	if o.OutputFile == "" && output.IsTerminal(os.Stdout) {
+		o.Output = "table"
+	}
+}
 
-	// now serialize to the specified format
-	serialized, err := output.SerializeResults(processed, o.Output)
+// TemplateText returns the output template, read from TemplateFile if set.
+func (o *OutputOptions) TemplateText() (string, error) {
+	if o.TemplateFile == "" {
+		return o.Template, nil
+	}
+	data, err := os.ReadFile(o.TemplateFile)
 	if err != nil {
-		return fmt.Errorf("failed to serialize results: %w", err)
+		return "", fmt.Errorf("error reading template file '%s': %w", o.TemplateFile, err)
+	}
+	return string(data), nil
+}
+
+// formatter returns the formatter of the specified output format.
+func (o *OutputOptions) formatter() (output.Formatter, error) {
+	switch o.Output {
+	case "ndjson":
+		return output.NewNDJSONFormatter(o.FullHit), nil
+	case "csv":
+		return output.NewCSVFormatter(',', o.Fields, !o.NoHeader, o.ArrayDelimiter), nil
+	case "tsv":
+		return output.NewCSVFormatter('\t', o.Fields, !o.NoHeader, o.ArrayDelimiter), nil
+	case "table":
+		width, color := 0, false
+		if o.OutputFile == "" {
+			width, color = output.TerminalWidth(os.Stdout), output.UseColor(os.Stdout)
+		}
+		return output.NewTableFormatter(o.Fields, width, color, o.ArrayDelimiter), nil
+	case "template":
+		text, err := o.TemplateText()
+		if err != nil {
+			return nil, err
+		}
+		tmpl, err := output.ParseTemplate(text, o.OutputFile == "" && output.UseColor(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %w", err)
+		}
+		return output.NewTemplateFormatter(tmpl, o.TemplateResponse), nil
+	default:
+		return output.NewSerializingFormatter(o.Output), nil
 	}
+}
 
+// NewResultWriter opens the output file, or stdout, for writing results.
+func (o *OutputOptions) NewResultWriter() (*ResultWriter, error) {
 	outputFile := o.OutputFile
 	if outputFile == "" {
 		outputFile = "*stdout"
 	}
-	return output.WriteToFile(serialized, outputFile)
+	formatter, err := o.formatter()
+	if err != nil {
+		return nil, err
+	}
+	w, err := output.OpenWriter(outputFile)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultWriter{opts: o, w: w, formatter: formatter}, nil
+}
+
+// Write processes and writes a result set in the specified format.
+func (rw *ResultWriter) Write(results any) error {
+	processed, err := rw.opts.processResults(results)
+	if err != nil {
+		return err
+	}
+
+	// now serialize to the specified format
+	if err := rw.formatter.Format(rw.w, processed); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	return nil
+}
+
+// WritePage processes and writes a page of a paginated result set, which
+// Finish completes. Formats that cannot hold several pages in one result set
+// write each page like Write, and so does a jq expression, whose results for
+// a page are not hits of the result set.
+func (rw *ResultWriter) WritePage(page any) error {
+	processed, err := rw.opts.processResults(page)
+	if err != nil {
+		return err
+	}
+
+	if pf, ok := rw.formatter.(output.PageFormatter); ok && rw.opts.JqPath == "" {
+		err = pf.FormatPage(rw.w, processed)
+	} else {
+		err = rw.formatter.Format(rw.w, processed)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	return nil
+}
+
+// Finish completes a paginated result set written with WritePage, with the
+// summary of all pages and the number of hits shown.
+func (rw *ResultWriter) Finish(summary map[string]any, shown int) error {
+	pf, ok := rw.formatter.(output.PageFormatter)
+	if !ok || rw.opts.JqPath != "" {
+		return nil
+	}
+	if err := pf.Finish(rw.w, summary, shown); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	return nil
+}
+
+// WriteHits writes the hits of a result set as newline-delimited JSON,
+// regardless of the output format.
+func (rw *ResultWriter) WriteHits(results any) error {
+	if err := output.NewNDJSONFormatter(rw.opts.FullHit).Format(rw.w, results); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	return nil
+}
+
+// Close closes the underlying output.
+func (rw *ResultWriter) Close() error {
+	return rw.w.Close()
+}
+
+// OutputResults processes and outputs the results to the specified format and file.
+func (o *OutputOptions) OutputResults(results any) error {
+	rw, err := o.NewResultWriter()
+	if err != nil {
+		return err
+	}
+	if err := rw.Write(results); err != nil {
+		rw.Close()
+		return err
+	}
+	return rw.Close()
 }
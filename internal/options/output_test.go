@@ -0,0 +1,62 @@
+package options
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultWriter_Pages(t *testing.T) {
+	hit := func(id string) map[string]any {
+		return map[string]any{"_id": id}
+	}
+	pages := []map[string]any{
+		{"took": 1.0, "total": 5.0, "hits": []any{hit("a"), hit("b")}},
+		{"took": 2.0, "total": 5.0, "hits": []any{hit("c"), hit("d")}},
+		{"took": 3.0, "total": 5.0, "hits": []any{hit("e")}},
+	}
+	summary := map[string]any{"took": 6.0, "total": 5.0}
+
+	testCases := []struct {
+		name string
+		jq   string
+		want string
+	}{
+		{
+			name: "Single Document",
+			want: "{\n  \"hits\": [\n    {\n      \"_id\": \"a\"\n    },\n    {\n      \"_id\": \"b\"\n    },\n    {\n      \"_id\": \"c\"\n    },\n    {\n      \"_id\": \"d\"\n    },\n    {\n      \"_id\": \"e\"\n    }\n  ],\n  \"took\": 6,\n  \"total\": 5\n}\n",
+		},
+		{
+			name: "JQ on each Page",
+			jq:   ".hits | length",
+			want: "2\n2\n1\n",
+		},
+		{
+			name: "JQ Stream",
+			jq:   "[.hits[]._id] | join(\",\")",
+			want: "\"a,b\"\n\"c,d\"\n\"e\"\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "out.json")
+			opts := OutputOptions{Output: "json", JqPath: tc.jq, OutputFile: file}
+			rw, err := opts.NewResultWriter()
+			require.NoError(t, err)
+
+			for _, page := range pages {
+				require.NoError(t, rw.WritePage(page))
+			}
+			require.NoError(t, rw.Finish(summary, 5))
+			require.NoError(t, rw.Close())
+
+			data, err := os.ReadFile(file)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, string(data))
+		})
+	}
+}
@@ -11,6 +11,8 @@ import (
 
 	"github.com/elastic/go-elasticsearch/v9/typedapi/types"
 	"github.com/elastic/go-elasticsearch/v9/typedapi/types/enums/operator"
+
+	"github.com/fa7ad/esq/internal/kql"
 )
 
 // QueryOptions holds query-related fields.
@@ -19,41 +21,89 @@ type QueryOptions struct {
 	DSL       string
 	Lucene    string
 	QueryFile string
+	// ESQL is an ES|QL query, run with the _query API instead of a search,
+	// with ESQLParams as its positional parameters.
+	ESQL       string
+	ESQLParams []string
+	// EQL is an EQL query, run with the EQL search API.
+	EQL string
+	// SQL is an Elasticsearch SQL query, run with the _sql API. SQLTranslate
+	// prints the search it translates to instead of running it.
+	SQL          string
+	SQLTranslate bool
 
 	From string
 	To   string
+	// TimeField is the field --from and --to apply to, detected from the
+	// index mapping if empty.
+	TimeField string
 
 	Size int
+
+	// All and Limit switch the search to paging through every matching
+	// document with a point in time, Size then being the page size.
+	All   bool
+	Limit int
+
+	// Count counts the matching documents instead of fetching them.
+	Count bool
+	// FailOnEmpty makes esq exit with exitcode.NoHits if nothing matched.
+	FailOnEmpty bool
+
+	AggregationOptions
+	FieldOptions
+	FilterOptions
+	EQLOptions
 }
 
-// normalize normalizes the query options into a single DSL query.
-func (q *QueryOptions) normalize() (string, error) {
+// DefaultTimeField is the time field used when none was configured or detected.
+const DefaultTimeField = "timestamp"
+
+// NeedsTimeField reports whether the query filters on the time field.
+func (q *QueryOptions) NeedsTimeField() bool {
+	return q.From != "" || q.To != ""
+}
+
+// HasQuery reports whether any query language option was provided.
+func (q *QueryOptions) HasQuery() bool {
+	return q.KQL != "" || q.DSL != "" || q.Lucene != "" || q.QueryFile != "" || q.ESQL != "" || q.EQL != "" || q.SQL != ""
+}
+
+// Paginate reports whether the search should page through results instead of
+// issuing a single request.
+func (q *QueryOptions) Paginate() bool {
+	return q.All || q.Limit > 0
+}
+
+// SearchRequestBody normalizes the query options into a single search request body.
+func (q *QueryOptions) SearchRequestBody() (*types.SearchRequestBody, error) {
 	queryBody := types.SearchRequestBody{
-		Query: &types.Query{},
+		Query: &types.Query{MatchAll: &types.MatchAllQuery{}},
 	}
 
 	switch {
 	case q.QueryFile != "":
 		data, err := os.ReadFile(q.QueryFile)
 		if err != nil {
-			return "", fmt.Errorf("error reading query file '%s': %w", q.QueryFile, err)
+			return nil, fmt.Errorf("error reading query file '%s': %w", q.QueryFile, err)
 		}
 		q.DSL = strings.TrimSpace(string(data))
 		fallthrough
 	case q.DSL != "":
 		var dslBody types.SearchRequestBody
 		if err := json.Unmarshal([]byte(q.DSL), &dslBody); err != nil {
-			return "", fmt.Errorf("invalid JSON for DSL query: %w", err)
+			return nil, fmt.Errorf("invalid JSON for DSL query: %w", err)
+		}
+		if dslBody.Query == nil {
+			dslBody.Query = queryBody.Query
 		}
 		queryBody = dslBody
 	case q.KQL != "":
-		queryBody.Query = &types.Query{
-			QueryString: &types.QueryStringQuery{
-				Query:           q.KQL,
-				AnalyzeWildcard: ptr.To(true),
-				Lenient:         ptr.To(true),
-			},
+		kqlQuery, err := kql.Compile(q.KQL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid KQL query: %w", err)
 		}
+		queryBody.Query = kqlQuery
 	case q.Lucene != "":
 		queryBody.Query = &types.Query{
 			QueryString: &types.QueryStringQuery{
@@ -64,38 +114,82 @@ func (q *QueryOptions) normalize() (string, error) {
 		}
 	}
 
-	var tsQuery *types.Query
-	if q.From != "" || q.To != "" {
-		tsRange := types.DateRangeQuery{}
-		if q.From != "" {
-			tsRange.Gte = &q.From
-		}
-		if q.To != "" {
-			tsRange.Lte = &q.To
-		}
-		tsQuery = &types.Query{
-			Range: map[string]types.RangeQuery{
-				"timestamp": &tsRange,
+	if tsQuery := q.timeRangeQuery(); tsQuery != nil {
+		queryBody.Query = &types.Query{
+			Bool: &types.BoolQuery{
+				Must: []types.Query{
+					*queryBody.Query,
+					*tsQuery,
+				},
 			},
 		}
 	}
 
-	if tsQuery != nil {
-		existingQuery := queryBody.Query
-		isQueryEmpty := q.KQL == "" && q.DSL == "" && q.Lucene == "" && q.QueryFile == ""
-		if isQueryEmpty {
-			existingQuery = &types.Query{MatchAll: &types.MatchAllQuery{}}
+	if q.HasFilters() {
+		filter, mustNot, err := q.FilterClauses()
+		if err != nil {
+			return nil, err
 		}
 		queryBody.Query = &types.Query{
 			Bool: &types.BoolQuery{
-				Must: []types.Query{
-					*existingQuery,
-					*tsQuery,
-				},
+				Must:    []types.Query{*queryBody.Query},
+				Filter:  filter,
+				MustNot: mustNot,
 			},
 		}
 	}
 
+	if err := q.FieldOptions.UpdateBody(&queryBody); err != nil {
+		return nil, err
+	}
+
+	if q.HasAggregations() {
+		aggs, err := q.Aggregations()
+		if err != nil {
+			return nil, err
+		}
+		if queryBody.Aggregations == nil {
+			queryBody.Aggregations = map[string]types.Aggregations{}
+		}
+		for name, agg := range aggs {
+			queryBody.Aggregations[name] = agg
+		}
+	}
+
+	return &queryBody, nil
+}
+
+// timeRangeQuery returns the range query of --from and --to on the time
+// field, or nil if neither is set.
+func (q *QueryOptions) timeRangeQuery() *types.Query {
+	if q.From == "" && q.To == "" {
+		return nil
+	}
+	tsRange := types.DateRangeQuery{}
+	if q.From != "" {
+		tsRange.Gte = &q.From
+	}
+	if q.To != "" {
+		tsRange.Lte = &q.To
+	}
+	timeField := q.TimeField
+	if timeField == "" {
+		timeField = DefaultTimeField
+	}
+	return &types.Query{
+		Range: map[string]types.RangeQuery{
+			timeField: &tsRange,
+		},
+	}
+}
+
+// normalize normalizes the query options into a single DSL query.
+func (q *QueryOptions) normalize() (string, error) {
+	queryBody, err := q.SearchRequestBody()
+	if err != nil {
+		return "", err
+	}
+
 	jsonData, err := json.Marshal(queryBody)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal query: %w", err)
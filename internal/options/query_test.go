@@ -23,9 +23,15 @@ func TestQueryOptions_normalize(t *testing.T) {
 		wantNotContain []string
 	}{
 		{
-			name:        "KQL only",
-			opts:        QueryOptions{KQL: "user:test"},
-			wantContain: []string{`"query_string"`, `"query":"user:test"`, `"analyze_wildcard":true`},
+			name:           "KQL only",
+			opts:           QueryOptions{KQL: "user:test"},
+			wantContain:    []string{`"match":{"user":{"query":"test"}}`},
+			wantNotContain: []string{`"query_string"`},
+		},
+		{
+			name:        "KQL boolean and range",
+			opts:        QueryOptions{KQL: "status:(200 or 404) and not response >= 400"},
+			wantContain: []string{`"should"`, `"must_not"`, `"range":{"response":{"gte":"400"}}`},
 		},
 		{
 			name: "Lucene only",
@@ -38,11 +44,122 @@ func TestQueryOptions_normalize(t *testing.T) {
 			opts:        QueryOptions{KQL: "user:test", From: "now-1h", To: "now"},
 			wantContain: []string{`"bool"`, `"must"`, `"range"`, `"timestamp"`, `"gte":"now-1h"`, `"lte":"now"`},
 		},
+		{
+			name:           "Time range on configured field",
+			opts:           QueryOptions{KQL: "user:test", From: "now-1h", TimeField: "@timestamp"},
+			wantContain:    []string{`"range":{"@timestamp":{"gte":"now-1h"}}`},
+			wantNotContain: []string{`"timestamp":`},
+		},
 		{
 			name:        "Time range only",
 			opts:        QueryOptions{From: "2025-01-01T00:00:00Z"},
 			wantContain: []string{`"bool"`, `"must"`, `"range"`, `"timestamp"`, `"match_all"`},
 		},
+		{
+			name: "Aggregation shortcuts without query",
+			opts: QueryOptions{AggregationOptions: AggregationOptions{
+				Terms:         []string{"status:5"},
+				DateHistogram: []string{"@timestamp:1h", "event.created:90m"},
+				Stats:         []string{"bytes"},
+			}},
+			wantContain: []string{
+				`"match_all"`,
+				`"terms_status":{"terms":{"field":"status","size":5}}`,
+				`"date_histogram_@timestamp":{"date_histogram":{"calendar_interval":"1h","field":"@timestamp"}}`,
+				`"date_histogram_event.created":{"date_histogram":{"field":"event.created","fixed_interval":"90m"}}`,
+				`"stats_bytes":{"stats":{"field":"bytes"}}`,
+			},
+		},
+		{
+			name: "Aggregation shortcuts merged into DSL aggregations",
+			opts: QueryOptions{
+				DSL:                `{"aggs":{"levels":{"terms":{"field":"level"}}}}`,
+				AggregationOptions: AggregationOptions{Cardinality: []string{"user.id"}},
+			},
+			wantContain: []string{`"levels":{"terms"`, `"cardinality_user.id":{"cardinality":{"field":"user.id"}}`, `"match_all"`},
+		},
+		{
+			name: "Filters wrapped around KQL",
+			opts: QueryOptions{
+				KQL: "level:error",
+				FilterOptions: FilterOptions{
+					Filter:  []string{"env=prod", "message=disk full, retrying"},
+					Exclude: []string{"host.name=web-1"},
+					Exists:  []string{"trace.id"},
+					Missing: []string{"user.id"},
+					Range:   []string{"bytes>=1024", "duration < 5"},
+				},
+			},
+			wantContain: []string{
+				`"must":[{"match":{"level":{"query":"error"}}}]`,
+				`"filter":[{"match_phrase":{"env":{"query":"prod"}}},{"match_phrase":{"message":{"query":"disk full, retrying"}}},{"range":{"bytes":{"gte":"1024"}}},{"range":{"duration":{"lt":"5"}}},{"exists":{"field":"trace.id"}}]`,
+				`"must_not":[{"match_phrase":{"host.name":{"query":"web-1"}}},{"exists":{"field":"user.id"}}]`,
+			},
+		},
+		{
+			name: "Filters wrapped around DSL with time range",
+			opts: QueryOptions{
+				DSL:           `{"query":{"term":{"level":"error"}}}`,
+				From:          "now-1h",
+				FilterOptions: FilterOptions{Filter: []string{"env=prod"}},
+			},
+			wantContain: []string{
+				`"must":[{"bool":{"must":[{"term":{"level":{"value":"error"}}},{"range":{"timestamp":{"gte":"now-1h"}}}]}}]`,
+				`"filter":[{"match_phrase":{"env":{"query":"prod"}}}]`,
+			},
+		},
+		{
+			name: "Field options with KQL",
+			opts: QueryOptions{
+				KQL: "level:error",
+				FieldOptions: FieldOptions{
+					SourceIncludes: []string{"message", "host.*"},
+					SourceExcludes: []string{"host.ip"},
+					FetchFields:    []string{"_id", "duration_ms"},
+					DocvalueFields: []string{"@timestamp"},
+					Sort:           []string{"@timestamp:desc", "host.name"},
+				},
+			},
+			wantContain: []string{
+				`"_source":{"excludes":["host.ip"],"includes":["message","host.*"]}`,
+				`"fields":[{"field":"duration_ms","include_unmapped":true}]`,
+				`"docvalue_fields":[{"field":"@timestamp"}]`,
+				`"sort":[{"@timestamp":{"order":"desc"}},{"host.name":{"order":"asc"}}]`,
+			},
+			wantNotContain: []string{`"field":"_id"`},
+		},
+		{
+			name: "Fields without Source Filter",
+			opts: QueryOptions{
+				KQL:          "level:error",
+				FieldOptions: FieldOptions{FetchFields: []string{"_id", "message"}},
+			},
+			wantContain: []string{
+				`"fields":[{"field":"message","include_unmapped":true}]`,
+				`"_source":false`,
+			},
+		},
+		{
+			name: "DSL Source with Fields",
+			opts: QueryOptions{
+				DSL:          `{"query":{"term":{"level":"error"}},"_source":["message"]}`,
+				FieldOptions: FieldOptions{FetchFields: []string{"host.name"}},
+			},
+			wantContain:    []string{`"_source":["message"]`},
+			wantNotContain: []string{`"_source":false`},
+		},
+		{
+			name: "Field options merged into DSL",
+			opts: QueryOptions{
+				DSL:          `{"query":{"term":{"level":"error"}},"sort":["_score"],"fields":["message"],"_source":false}`,
+				FieldOptions: FieldOptions{FetchFields: []string{"host.name"}, Sort: []string{"@timestamp"}},
+			},
+			wantContain: []string{
+				`"fields":[{"field":"message"},{"field":"host.name","include_unmapped":true}]`,
+				`"sort":[{"@timestamp":{"order":"asc"}}]`,
+				`"_source":false`,
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,33 @@
+package options
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v9/typedapi/types"
+)
+
+// sqlRequest is the body of an SQL query, or translate, request.
+type sqlRequest struct {
+	Query     string       `json:"query"`
+	FetchSize int          `json:"fetch_size,omitempty"`
+	Filter    *types.Query `json:"filter,omitempty"`
+}
+
+// SQLRequestBody builds the body of the --sql query, fetching Size rows per
+// page, with --from, --to and the filter flags as its DSL filter.
+func (q *QueryOptions) SQLRequestBody() ([]byte, error) {
+	req := sqlRequest{Query: q.SQL, FetchSize: q.Size}
+
+	filter, err := q.FilterQuery()
+	if err != nil {
+		return nil, err
+	}
+	req.Filter = filter
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SQL query: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,42 @@
+package options
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryOptions_SQLRequestBody(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts QueryOptions
+		want string
+	}{
+		{
+			name: "Query only",
+			opts: QueryOptions{SQL: "SELECT * FROM logs", Size: 100},
+			want: `{"query":"SELECT * FROM logs","fetch_size":100}`,
+		},
+		{
+			name: "Time range and filters",
+			opts: QueryOptions{
+				SQL:           "SELECT level, COUNT(*) FROM logs GROUP BY level",
+				Size:          50,
+				From:          "now-1h",
+				TimeField:     "@timestamp",
+				FilterOptions: FilterOptions{Exclude: []string{"env=dev"}},
+			},
+			want: `{"query":"SELECT level, COUNT(*) FROM logs GROUP BY level","fetch_size":50,
+				"filter":{"bool":{"filter":[{"range":{"@timestamp":{"gte":"now-1h"}}}],"must_not":[{"match_phrase":{"env":{"query":"dev"}}}]}}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.opts.SQLRequestBody()
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package options
+
+import "time"
+
+// TailOptions holds fields of the tail command.
+type TailOptions struct {
+	Follow   bool
+	Interval time.Duration
+
+	// TiebreakerField orders documents sharing a timestamp. Without a
+	// tiebreaker, documents already seen at the latest timestamp are skipped by _id.
+	TiebreakerField string
+}
@@ -0,0 +1,91 @@
+package options
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fa7ad/esq/internal/exitcode"
+)
+
+// TLSOptions holds the TLS settings of the connection to Elasticsearch.
+type TLSOptions struct {
+	// CACert is a PEM file of the certificate authorities trusted instead of the system ones.
+	CACert string
+	// ClientCert and ClientKey are PEM files of the client certificate for mutual TLS.
+	ClientCert string
+	ClientKey  string
+	// CAFingerprint is the SHA-256 fingerprint of a certificate the server
+	// must present, such as the one printed by Elasticsearch on first launch.
+	CAFingerprint      string
+	InsecureSkipVerify bool
+}
+
+// HasTLSOptions reports whether any TLS setting was provided.
+func (t *TLSOptions) HasTLSOptions() bool {
+	return t.CACert != "" || t.ClientCert != "" || t.ClientKey != "" || t.CAFingerprint != "" || t.InsecureSkipVerify
+}
+
+// Fingerprint returns CAFingerprint as lowercase hex without separators, so
+// that both "AB:CD:..." and "abcd..." are accepted.
+func (t *TLSOptions) Fingerprint() string {
+	return strings.ToLower(strings.ReplaceAll(t.CAFingerprint, ":", ""))
+}
+
+// TLSConfig builds the TLS configuration of the connection, or returns nil if
+// no TLS setting was provided.
+func (t *TLSOptions) TLSConfig() (*tls.Config, error) {
+	if !t.HasTLSOptions() {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{InsecureSkipVerify: t.InsecureSkipVerify}
+
+	if t.CACert != "" {
+		pem, err := os.ReadFile(t.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA certificate '%s': %w", t.CACert, err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificate found in CA certificate '%s'", t.CACert)
+		}
+	}
+
+	if t.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(t.ClientCert, t.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if t.CAFingerprint != "" {
+		fingerprint, err := hex.DecodeString(t.Fingerprint())
+		if err != nil {
+			return nil, fmt.Errorf("invalid CA fingerprint: %w", err)
+		}
+		// the pinned certificate replaces the verification of the chain,
+		// unless a CA certificate is also given
+		if t.CACert == "" {
+			cfg.InsecureSkipVerify = true
+		}
+		cfg.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			for _, raw := range rawCerts {
+				digest := sha256.Sum256(raw)
+				if bytes.Equal(digest[:], fingerprint) {
+					return nil
+				}
+			}
+			return exitcode.Wrap(exitcode.Connection,
+				fmt.Errorf("no server certificate matches the CA fingerprint %s", t.CAFingerprint))
+		}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,75 @@
+package options
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSOptions_TLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	serverCert := server.Certificate()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverCert.Raw})
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0600))
+
+	digest := sha256.Sum256(serverCert.Raw)
+	fingerprint := hex.EncodeToString(digest[:])
+	colonFingerprint := strings.ToUpper(strings.Join(splitPairs(fingerprint), ":"))
+	otherFingerprint := strings.Repeat("ab", 32)
+
+	testCases := []struct {
+		name        string
+		opts        TLSOptions
+		wantNil     bool
+		wantConnErr bool
+	}{
+		{"No TLS Options", TLSOptions{}, true, false},
+		{"CA Certificate", TLSOptions{CACert: caFile}, false, false},
+		{"Fingerprint", TLSOptions{CAFingerprint: fingerprint}, false, false},
+		{"Fingerprint with Colons", TLSOptions{CAFingerprint: colonFingerprint}, false, false},
+		{"Mismatched Fingerprint", TLSOptions{CAFingerprint: otherFingerprint}, false, true},
+		{"CA Certificate and Mismatched Fingerprint", TLSOptions{CACert: caFile, CAFingerprint: otherFingerprint}, false, true},
+		{"Insecure", TLSOptions{InsecureSkipVerify: true}, false, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := tc.opts.TLSConfig()
+			require.NoError(t, err)
+			if tc.wantNil {
+				assert.Nil(t, cfg)
+				return
+			}
+
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}
+			res, err := client.Get(server.URL)
+			if tc.wantConnErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			res.Body.Close()
+		})
+	}
+}
+
+// splitPairs splits a hex string into pairs of digits.
+func splitPairs(s string) []string {
+	pairs := make([]string, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		pairs = append(pairs, s[i:i+2])
+	}
+	return pairs
+}
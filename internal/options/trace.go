@@ -0,0 +1,8 @@
+package options
+
+// TraceOptions configure the logging of requests to Elasticsearch on stderr.
+type TraceOptions struct {
+	// Verbose is 1 to log each request with its status, latency and response
+	// size, and 2 to also log headers and bodies.
+	Verbose int
+}
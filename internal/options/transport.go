@@ -0,0 +1,67 @@
+package options
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v9"
+)
+
+// maxRetryBackoff caps the exponential backoff between retries.
+const maxRetryBackoff = 30 * time.Second
+
+// DefaultRetryOnStatus are the response statuses retried by default.
+var DefaultRetryOnStatus = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// TransportOptions holds the node discovery and retry policy of the client.
+type TransportOptions struct {
+	// Sniff discovers the nodes of the cluster on start, and every
+	// SniffInterval if set.
+	Sniff         bool
+	SniffInterval time.Duration
+
+	// MaxRetries requests are retried on RetryOnStatus responses and on
+	// connection errors, waiting RetryBackoff, doubled on each retry.
+	MaxRetries    int
+	RetryOnStatus []int
+	RetryBackoff  time.Duration
+
+	// Timeout bounds each request attempt, zero meaning no timeout.
+	Timeout time.Duration
+}
+
+// Backoff returns how long to wait before the given retry, starting at 1.
+func (t *TransportOptions) Backoff(attempt int) time.Duration {
+	backoff := t.RetryBackoff
+	for i := 1; i < attempt && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxRetryBackoff)
+}
+
+// UpdateConfig applies the node discovery and retry policy to the client config.
+func (t *TransportOptions) UpdateConfig(receiver *elasticsearch.Config) {
+	receiver.DiscoverNodesOnStart = t.Sniff
+	if t.Sniff {
+		receiver.DiscoverNodesInterval = t.SniffInterval
+	}
+
+	if t.MaxRetries == 0 {
+		receiver.DisableRetry = true
+		return
+	}
+	receiver.MaxRetries = t.MaxRetries
+	receiver.RetryOnStatus = t.RetryOnStatus
+	if t.RetryBackoff > 0 {
+		receiver.RetryBackoff = t.Backoff
+	}
+	// connection errors are retried, unless the search was cancelled
+	receiver.RetryOnError = func(req *http.Request, err error) bool {
+		return req.Context().Err() == nil
+	}
+}
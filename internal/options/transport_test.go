@@ -0,0 +1,61 @@
+package options
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransportOptions_Backoff(t *testing.T) {
+	opts := TransportOptions{RetryBackoff: 500 * time.Millisecond}
+
+	testCases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 500 * time.Millisecond},
+		{2, time.Second},
+		{4, 4 * time.Second},
+		{10, maxRetryBackoff},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, opts.Backoff(tc.attempt), "attempt %d", tc.attempt)
+	}
+}
+
+func TestTransportOptions_UpdateConfig(t *testing.T) {
+	testCases := []struct {
+		name             string
+		opts             TransportOptions
+		wantDisableRetry bool
+		wantMaxRetries   int
+		wantSniffEvery   time.Duration
+	}{
+		{
+			name:           "Retries with sniffing",
+			opts:           TransportOptions{Sniff: true, SniffInterval: time.Minute, MaxRetries: 5, RetryOnStatus: DefaultRetryOnStatus},
+			wantMaxRetries: 5,
+			wantSniffEvery: time.Minute,
+		},
+		{
+			name:             "No retries",
+			opts:             TransportOptions{SniffInterval: time.Minute},
+			wantDisableRetry: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &elasticsearch.Config{}
+			tc.opts.UpdateConfig(cfg)
+
+			assert.Equal(t, tc.wantDisableRetry, cfg.DisableRetry)
+			assert.Equal(t, tc.wantMaxRetries, cfg.MaxRetries)
+			assert.Equal(t, tc.opts.Sniff, cfg.DiscoverNodesOnStart)
+			assert.Equal(t, tc.wantSniffEvery, cfg.DiscoverNodesInterval)
+		})
+	}
+}
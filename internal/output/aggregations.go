@@ -0,0 +1,159 @@
+package output
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AggregationTables converts the aggregations of a search response into
+// tables, sorted by name. Bucket aggregations have one row per bucket, with
+// the bucket key, its document count and its sub-aggregations as columns;
+// metric aggregations have a single row of their values.
+func AggregationTables(results any) []NamedTable {
+	response, ok := results.(map[string]any)
+	if !ok {
+		return nil
+	}
+	aggs, ok := response["aggregations"].(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(aggs))
+	for name := range aggs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tables := make([]NamedTable, 0, len(names))
+	for _, name := range names {
+		agg, ok := aggs[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		tables = append(tables, NamedTable{Name: name, Table: aggregationTable(agg)})
+	}
+	return tables
+}
+
+func aggregationTable(agg map[string]any) Table {
+	var records []map[string]any
+	switch buckets := agg["buckets"].(type) {
+	case []any:
+		for _, b := range buckets {
+			if bucket, ok := b.(map[string]any); ok {
+				records = append(records, bucketRecord(bucket, bucket["key"]))
+			}
+		}
+	case map[string]any: // keyed buckets, e.g. of a filters aggregation
+		keys := make([]string, 0, len(buckets))
+		for key := range buckets {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if bucket, ok := buckets[key].(map[string]any); ok {
+				records = append(records, bucketRecord(bucket, key))
+			}
+		}
+	default:
+		records = []map[string]any{metricRecord(agg)}
+	}
+
+	columns := recordColumns(records)
+	rows := make([][]any, len(records))
+	for i, record := range records {
+		rows[i] = make([]any, len(columns))
+		for j, col := range columns {
+			rows[i][j] = record[col]
+		}
+	}
+	return Table{Columns: columns, Rows: rows}
+}
+
+// bucketRecord flattens a bucket into its key, its document count and the
+// values of its sub-aggregations, e.g. "avg_bytes" or "stats_bytes.max".
+func bucketRecord(bucket map[string]any, key any) map[string]any {
+	if keyText, ok := bucket["key_as_string"]; ok {
+		key = keyText
+	}
+	record := map[string]any{"key": key, "doc_count": bucket["doc_count"]}
+	for name, v := range bucket {
+		switch name {
+		case "key", "key_as_string", "doc_count":
+			continue
+		}
+		sub, ok := v.(map[string]any)
+		if !ok {
+			record[name] = v
+			continue
+		}
+		for col, value := range metricRecord(sub) {
+			if col == "value" {
+				record[name] = value
+			} else {
+				record[name+"."+col] = value
+			}
+		}
+	}
+	return record
+}
+
+// metricRecord flattens the values of a metric aggregation, such as
+// {"value": 42} or {"count": 3, "min": 1, ...}. Percentiles, returned as
+// {"values": {"50.0": ...}}, are keyed by percentile.
+func metricRecord(agg map[string]any) map[string]any {
+	record := map[string]any{}
+	for k, v := range agg {
+		switch {
+		case k == "meta" || strings.HasSuffix(k, "_as_string"):
+			continue
+		case k == "values":
+			if values, ok := v.(map[string]any); ok {
+				for percentile, value := range values {
+					record[percentile] = value
+				}
+				continue
+			}
+		}
+		record[k] = v
+	}
+	return record
+}
+
+// recordColumns returns the union of the record keys, the bucket key and
+// document count first, numeric keys such as percentiles in numeric order,
+// and other keys sorted.
+func recordColumns(records []map[string]any) []string {
+	seen := map[string]bool{}
+	for _, record := range records {
+		for k := range record {
+			seen[k] = true
+		}
+	}
+
+	var columns []string
+	for _, col := range []string{"key", "doc_count"} {
+		if seen[col] {
+			columns = append(columns, col)
+			delete(seen, col)
+		}
+	}
+	rest := make([]string, 0, len(seen))
+	for k := range seen {
+		rest = append(rest, k)
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		a, errA := strconv.ParseFloat(rest[i], 64)
+		b, errB := strconv.ParseFloat(rest[j], 64)
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		if (errA == nil) != (errB == nil) {
+			return errA == nil
+		}
+		return rest[i] < rest[j]
+	})
+	return append(columns, rest...)
+}
@@ -0,0 +1,78 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregationTables(t *testing.T) {
+	testCases := []struct {
+		name string
+		aggs map[string]any
+		want []NamedTable
+	}{
+		{
+			name: "Terms buckets with sub-aggregations",
+			aggs: map[string]any{"terms_status": map[string]any{
+				"sum_other_doc_count": 0.0,
+				"buckets": []any{
+					map[string]any{"key": "200", "doc_count": 7.0, "avg_bytes": map[string]any{"value": 512.0}},
+					map[string]any{"key": "404", "doc_count": 2.0, "avg_bytes": map[string]any{"value": nil}},
+				},
+			}},
+			want: []NamedTable{{Name: "terms_status", Table: Table{
+				Columns: []string{"key", "doc_count", "avg_bytes"},
+				Rows:    [][]any{{"200", 7.0, 512.0}, {"404", 2.0, nil}},
+			}}},
+		},
+		{
+			name: "Date histogram keyed by date",
+			aggs: map[string]any{"date_histogram_@timestamp": map[string]any{
+				"buckets": []any{
+					map[string]any{"key": 1.7e12, "key_as_string": "2025-01-01T00:00:00.000Z", "doc_count": 3.0},
+				},
+			}},
+			want: []NamedTable{{Name: "date_histogram_@timestamp", Table: Table{
+				Columns: []string{"key", "doc_count"},
+				Rows:    [][]any{{"2025-01-01T00:00:00.000Z", 3.0}},
+			}}},
+		},
+		{
+			name: "Keyed buckets",
+			aggs: map[string]any{"levels": map[string]any{
+				"buckets": map[string]any{
+					"warn":  map[string]any{"doc_count": 4.0},
+					"error": map[string]any{"doc_count": 1.0},
+				},
+			}},
+			want: []NamedTable{{Name: "levels", Table: Table{
+				Columns: []string{"key", "doc_count"},
+				Rows:    [][]any{{"error", 1.0}, {"warn", 4.0}},
+			}}},
+		},
+		{
+			name: "Metric aggregations sorted by name",
+			aggs: map[string]any{
+				"stats_bytes":       map[string]any{"count": 2.0, "min": 1.0, "max": 3.0, "avg": 2.0, "sum": 4.0},
+				"percentiles_took":  map[string]any{"values": map[string]any{"5.0": 1.0, "50.0": 9.0, "99.0": 42.0}},
+				"cardinality_users": map[string]any{"value": 12.0},
+			},
+			want: []NamedTable{
+				{Name: "cardinality_users", Table: Table{Columns: []string{"value"}, Rows: [][]any{{12.0}}}},
+				{Name: "percentiles_took", Table: Table{Columns: []string{"5.0", "50.0", "99.0"}, Rows: [][]any{{1.0, 9.0, 42.0}}}},
+				{Name: "stats_bytes", Table: Table{
+					Columns: []string{"avg", "count", "max", "min", "sum"},
+					Rows:    [][]any{{2.0, 2.0, 3.0, 1.0, 4.0}},
+				}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := AggregationTables(map[string]any{"took": 1.0, "aggregations": tc.aggs})
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
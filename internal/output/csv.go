@@ -0,0 +1,146 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// DefaultArrayDelimiter joins array values in a single cell.
+const DefaultArrayDelimiter = ";"
+
+// csvFormatter writes results as delimiter-separated values.
+type csvFormatter struct {
+	comma rune
+	// fields are the selected columns, columns those written so far.
+	fields         []string
+	columns        []string
+	header         bool
+	arrayDelimiter string
+	// wroteHeader is set once the header of the rows was written.
+	wroteHeader bool
+}
+
+// NewCSVFormatter returns a formatter writing one row per document, separated
+// by comma. The columns are the given fields, or are inferred from the first
+// non-empty result set; later result sets with other columns, such as the
+// pages of a paginated search, are rejected. The aggregation tables of search
+// responses follow their hits, each with its own header, its rows led by the
+// name of the aggregation, and separated by an empty line. The events of EQL
+// sequences are led by the position and join keys of their sequence.
+func NewCSVFormatter(comma rune, fields []string, header bool, arrayDelimiter string) Formatter {
+	return &csvFormatter{
+		comma:          comma,
+		fields:         fields,
+		columns:        fields,
+		header:         header,
+		arrayDelimiter: arrayDelimiter,
+	}
+}
+
+func (f *csvFormatter) Format(w io.Writer, results any) error {
+	if sequences, ok := SequenceTable(results, f.columns, f.arrayDelimiter); ok {
+		return f.writeNamedTables(w, []NamedTable{{Table: sequences}})
+	}
+	table := ToTable(results, f.columns)
+	aggregations := namedRows(AggregationTables(results), "aggregation")
+	if len(table.Rows) == 0 {
+		return f.writeNamedTables(w, aggregations)
+	}
+
+	if f.columns == nil {
+		f.columns = table.Columns
+	} else if f.fields == nil {
+		// the rows would be written without the values of new columns
+		if added := newColumns(f.columns, resultColumns(results)); len(added) > 0 {
+			return fmt.Errorf("later results have new columns %s, select the columns with --fields", strings.Join(added, ", "))
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = f.comma
+	if f.header && !f.wroteHeader {
+		if err := cw.Write(table.Columns); err != nil {
+			return err
+		}
+		f.wroteHeader = true // only before the first result set
+	}
+
+	record := make([]string, len(table.Columns))
+	for _, row := range table.Rows {
+		for i, cell := range row {
+			record[i] = FormatCell(cell, f.arrayDelimiter)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+
+	if len(aggregations) == 0 {
+		return nil
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return err
+	}
+	return f.writeNamedTables(w, aggregations)
+}
+
+func (f *csvFormatter) writeNamedTables(w io.Writer, tables []NamedTable) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = f.comma
+
+	for i, table := range tables {
+		if i > 0 {
+			cw.Flush()
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if f.header {
+			if err := cw.Write(table.Columns); err != nil {
+				return err
+			}
+		}
+		record := make([]string, len(table.Columns))
+		for _, row := range table.Rows {
+			for j, cell := range row {
+				record[j] = FormatCell(cell, f.arrayDelimiter)
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// namedRows leads the rows of each table with its name, in the column of the
+// given name, so that the tables can be told apart without a header.
+func namedRows(tables []NamedTable, column string) []NamedTable {
+	out := make([]NamedTable, len(tables))
+	for i, t := range tables {
+		out[i] = NamedTable{Name: t.Name, Table: Table{Columns: append([]string{column}, t.Columns...)}}
+		for _, row := range t.Rows {
+			out[i].Rows = append(out[i].Rows, append([]any{t.Name}, row...))
+		}
+	}
+	return out
+}
+
+// newColumns returns the columns missing from the written ones.
+func newColumns(written, columns []string) []string {
+	var added []string
+	for _, col := range columns {
+		if !slices.Contains(written, col) {
+			added = append(added, col)
+		}
+	}
+	return added
+}
@@ -0,0 +1,119 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVFormatter(t *testing.T) {
+	page := func(sources ...map[string]any) map[string]any {
+		hits := make([]any, len(sources))
+		for i, s := range sources {
+			hits[i] = map[string]any{"_id": s["id"], "_source": s}
+		}
+		return map[string]any{"took": 1.0, "hits": hits}
+	}
+	first := page(
+		map[string]any{"id": "1", "user": map[string]any{"name": "bob"}, "tags": []any{"a", "b"}},
+		map[string]any{"id": "2", "user.name": "eve", "bytes": 1024.0},
+	)
+	second := page(map[string]any{"id": "3", "user": map[string]any{"name": "max"}, "bytes": 2048.0})
+
+	testCases := []struct {
+		name   string
+		comma  rune
+		fields []string
+		header bool
+		want   string
+	}{
+		{
+			name:   "Inferred columns",
+			comma:  ',',
+			header: true,
+			want:   "bytes,id,tags,user.name\n,1,a;b,bob\n1024,2,,eve\n2048,3,,max\n",
+		},
+		{
+			name:   "Selected fields without header",
+			comma:  ',',
+			fields: []string{"_id", "user.name"},
+			want:   "1,bob\n2,eve\n3,max\n",
+		},
+		{
+			name:   "TSV",
+			comma:  '\t',
+			fields: []string{"user.name", "tags"},
+			header: true,
+			want:   "user.name\ttags\nbob\ta;b\neve\t\nmax\t\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			f := NewCSVFormatter(tc.comma, tc.fields, tc.header, ";")
+			require.NoError(t, f.Format(&buf, first))
+			require.NoError(t, f.Format(&buf, second))
+			assert.Equal(t, tc.want, buf.String())
+		})
+	}
+}
+
+func TestCSVFormatter_NewColumns(t *testing.T) {
+	first := map[string]any{"hits": []any{map[string]any{"_source": map[string]any{"a": 1.0}}}}
+	second := map[string]any{"hits": []any{map[string]any{"_source": map[string]any{"a": 2.0}}}}
+	third := map[string]any{"hits": []any{map[string]any{"_source": map[string]any{"b": 3.0}}}}
+
+	var buf bytes.Buffer
+	f := NewCSVFormatter(',', nil, true, ";")
+	require.NoError(t, f.Format(&buf, first))
+	require.NoError(t, f.Format(&buf, second))
+	err := f.Format(&buf, third)
+	require.EqualError(t, err, "later results have new columns b, select the columns with --fields")
+	assert.Equal(t, "a\n1\n2\n", buf.String())
+}
+
+func TestCSVFormatter_Aggregations(t *testing.T) {
+	response := map[string]any{
+		"took": 1.0,
+		"aggregations": map[string]any{
+			"terms_level":      map[string]any{"buckets": []any{map[string]any{"key": "info", "doc_count": 2.0}}},
+			"cardinality_host": map[string]any{"value": 4.0},
+		},
+	}
+
+	testCases := []struct {
+		name string
+		hits []any
+		want string
+	}{
+		{
+			name: "Without Hits",
+			want: "aggregation,value\ncardinality_host,4\n\naggregation,key,doc_count\nterms_level,info,2\n",
+		},
+		{
+			name: "After Hits",
+			hits: []any{map[string]any{"_source": map[string]any{"level": "info"}}},
+			want: "level\ninfo\n\naggregation,value\ncardinality_host,4\n\naggregation,key,doc_count\nterms_level,info,2\n",
+		},
+	}
+
+	t.Run("TSV without Header", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, NewCSVFormatter('\t', nil, false, ";").Format(&buf, response))
+		assert.Equal(t, "cardinality_host\t4\n\nterms_level\tinfo\t2\n", buf.String())
+	})
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.hits != nil {
+				response["hits"] = tc.hits
+			}
+			var buf bytes.Buffer
+			require.NoError(t, NewCSVFormatter(',', nil, true, ";").Format(&buf, response))
+			assert.Equal(t, tc.want, buf.String())
+		})
+	}
+}
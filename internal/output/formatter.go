@@ -0,0 +1,119 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// Formatter writes result sets to an output in a specific format. A formatter
+// may be used for several result sets, e.g. the polls of tail, and may keep
+// state between them.
+type Formatter interface {
+	Format(w io.Writer, results any) error
+}
+
+// PageFormatter is implemented by formatters that write the pages of a
+// paginated search as a single result set, instead of one per page.
+type PageFormatter interface {
+	Formatter
+	// FormatPage writes the results of a page, or holds them back until Finish.
+	FormatPage(w io.Writer, page any) error
+	// Finish completes the result set after the last page. The summary holds
+	// the total hit count and duration of all pages, of which shown hits were
+	// written.
+	Finish(w io.Writer, summary map[string]any, shown int) error
+}
+
+// serializingFormatter writes each result set serialized by SerializeResults.
+type serializingFormatter struct {
+	format string
+
+	// pageHits counts the hits written by FormatPage, -1 before the first page.
+	pageHits int
+}
+
+// NewSerializingFormatter returns a formatter for the json and text formats.
+// The pages of a paginated search are written in the json format as a single
+// document, whose hits are those of every page.
+func NewSerializingFormatter(format string) Formatter {
+	return &serializingFormatter{format: format, pageHits: -1}
+}
+
+func (f *serializingFormatter) Format(w io.Writer, results any) error {
+	serialized, err := SerializeResults(results, f.format)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(serialized, '\n'))
+	return err
+}
+
+// FormatPage streams the hits of a page into the "hits" array of a JSON
+// document, which Finish closes.
+func (f *serializingFormatter) FormatPage(w io.Writer, page any) error {
+	if f.format != "json" {
+		return f.Format(w, page)
+	}
+
+	var b bytes.Buffer
+	if f.pageHits < 0 {
+		b.WriteString("{\n  \"hits\": [")
+		f.pageHits = 0
+	}
+	for _, doc := range documents(page) {
+		data, err := json.MarshalIndent(doc, "    ", "  ")
+		if err != nil {
+			return err
+		}
+		if f.pageHits > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n    ")
+		b.Write(data)
+		f.pageHits++
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// Finish closes the "hits" array and adds the summary of all pages to the
+// JSON document, in the key order of json.MarshalIndent.
+func (f *serializingFormatter) Finish(w io.Writer, summary map[string]any, shown int) error {
+	if f.format != "json" {
+		return nil
+	}
+
+	var b bytes.Buffer
+	switch {
+	case f.pageHits < 0:
+		b.WriteString("{\n  \"hits\": []")
+	case f.pageHits == 0:
+		b.WriteString("]")
+	default:
+		b.WriteString("\n  ]")
+	}
+
+	keys := make([]string, 0, len(summary))
+	for k := range summary {
+		if k != "hits" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		key, _ := json.Marshal(k)
+		value, err := json.MarshalIndent(summary[k], "  ", "  ")
+		if err != nil {
+			return err
+		}
+		b.WriteString(",\n  ")
+		b.Write(key)
+		b.WriteString(": ")
+		b.Write(value)
+	}
+	b.WriteString("\n}\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
@@ -0,0 +1,54 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerializingFormatter_Pages(t *testing.T) {
+	page := func(ids ...string) map[string]any {
+		hits := make([]any, len(ids))
+		for i, id := range ids {
+			hits[i] = map[string]any{"_id": id, "_source": map[string]any{"n": float64(i)}}
+		}
+		return map[string]any{"took": 1.0, "hits": hits}
+	}
+	summary := map[string]any{"took": 3.0, "total": map[string]any{"value": 3.0, "relation": "eq"}}
+
+	testCases := []struct {
+		name     string
+		pages    []any
+		wantHits int
+	}{
+		{"Several Pages", []any{page("1", "2"), page("3")}, 3},
+		{"Empty Page", []any{page()}, 0},
+		{"No Pages", nil, 0},
+		{"jq Results", []any{[]any{"a", "b"}, []any{"c"}}, 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			f := NewSerializingFormatter("json").(PageFormatter)
+			for _, p := range tc.pages {
+				require.NoError(t, f.FormatPage(&buf, p))
+			}
+			require.NoError(t, f.Finish(&buf, summary, tc.wantHits))
+
+			// a single document, indented like json.MarshalIndent
+			var got map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+			assert.Len(t, got["hits"], tc.wantHits)
+			assert.Equal(t, summary["total"], got["total"])
+
+			got["hits"] = Hits(got)
+			want, err := json.MarshalIndent(got, "", "  ")
+			require.NoError(t, err)
+			assert.Equal(t, string(want)+"\n", buf.String())
+		})
+	}
+}
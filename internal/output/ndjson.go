@@ -0,0 +1,77 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"maps"
+)
+
+// ndjsonFormatter writes one compact JSON document per line.
+type ndjsonFormatter struct {
+	fullHit bool
+}
+
+// NewNDJSONFormatter returns a formatter writing the _source of each hit, with
+// the fields returned by the fields API, or only those of a hit fetched
+// without its _source, or the full hit if fullHit is set, as one JSON line.
+// Results that are not search hits, e.g. produced by a jq expression, are
+// written as-is.
+func NewNDJSONFormatter(fullHit bool) Formatter {
+	return &ndjsonFormatter{fullHit: fullHit}
+}
+
+func (f *ndjsonFormatter) Format(w io.Writer, results any) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for _, doc := range documents(results) {
+		if hit, ok := doc.(map[string]any); ok && !f.fullHit {
+			source, hasSource := hit["_source"]
+			_, hasFields := hit["fields"]
+			_, hasID := hit["_id"]
+			if hasSource {
+				doc = source
+			}
+			// hits fetched without their _source have only fields
+			if hasFields && (hasSource || hasID) {
+				record := map[string]any{}
+				if source, ok := source.(map[string]any); ok {
+					maps.Copy(record, source)
+				}
+				mergeFields(record, hit)
+				doc = record
+			}
+		}
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// documents returns the hits of a search response, the elements of an array,
+// or any other value as a single document.
+func documents(results any) []any {
+	switch r := results.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		if isSearchResponse(r) {
+			return Hits(r)
+		}
+		return []any{r}
+	case []any:
+		return r
+	default:
+		return []any{results}
+	}
+}
+
+// isSearchResponse reports whether m is a search response. Responses without
+// matches may lack "hits" entirely, as filter_path drops empty arrays.
+func isSearchResponse(m map[string]any) bool {
+	_, hasHits := m["hits"].([]any)
+	_, hasShards := m["_shards"]
+	_, hasTook := m["took"]
+	return hasHits || hasShards || hasTook
+}
@@ -0,0 +1,76 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNDJSONFormatter(t *testing.T) {
+	response := map[string]any{
+		"took": 3,
+		"hits": []any{
+			map[string]any{"_id": "1", "_source": map[string]any{"msg": "a"}},
+			map[string]any{"_id": "2", "_source": map[string]any{"msg": "b"}},
+		},
+	}
+
+	testCases := []struct {
+		name    string
+		results any
+		fullHit bool
+		want    string
+	}{
+		{
+			name:    "Sources",
+			results: response,
+			want:    "{\"msg\":\"a\"}\n{\"msg\":\"b\"}\n",
+		},
+		{
+			name:    "Full hits",
+			results: response,
+			fullHit: true,
+			want:    "{\"_id\":\"1\",\"_source\":{\"msg\":\"a\"}}\n{\"_id\":\"2\",\"_source\":{\"msg\":\"b\"}}\n",
+		},
+		{
+			name: "Sources with fields",
+			results: map[string]any{"took": 1, "hits": []any{
+				map[string]any{"_id": "1", "_source": map[string]any{"msg": "a"}, "fields": map[string]any{"msg": []any{"a"}, "duration_ms": []any{12.0}}},
+			}},
+			want: "{\"duration_ms\":12,\"msg\":\"a\"}\n",
+		},
+		{
+			name: "Fields without sources",
+			results: map[string]any{"took": 1, "hits": []any{
+				map[string]any{"_id": "1", "_index": "logs", "fields": map[string]any{"host.name": []any{"web-1"}, "tags": []any{"a", "b"}}},
+			}},
+			want: "{\"host.name\":\"web-1\",\"tags\":[\"a\",\"b\"]}\n",
+		},
+		{
+			name:    "Response without hits",
+			results: map[string]any{"took": 1, "timed_out": false},
+			want:    "",
+		},
+		{
+			name:    "jq array",
+			results: []any{"a", 1.0},
+			want:    "\"a\"\n1\n",
+		},
+		{
+			name:    "jq object",
+			results: map[string]any{"id": "1"},
+			want:    "{\"id\":\"1\"}\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := NewNDJSONFormatter(tc.fullHit).Format(&buf, tc.results)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, buf.String())
+		})
+	}
+}
@@ -3,6 +3,7 @@ package output
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/itchyny/gojq"
@@ -48,19 +49,36 @@ func SerializeResults(results any, format string) ([]byte, error) {
 	}
 }
 
-// WriteToFile writes the serialized data to the specified output file or stdout.
-func WriteToFile(serialized []byte, outputFile string) error {
-	switch outputFile {
-	case "*stdout":
-		_, err := os.Stdout.Write(serialized)
-		if err != nil {
-			return fmt.Errorf("failed to write to stdout: %w", err)
-		}
+// Hits extracts the list of hits from search results. Results are either a
+// search response, whose "hits" were flattened into an array, or a bare array
+// of hits, e.g. after applying a jq expression.
+func Hits(results any) []any {
+	switch r := results.(type) {
+	case map[string]any:
+		hits, _ := r["hits"].([]any)
+		return hits
+	case []any:
+		return r
 	default:
-		err := os.WriteFile(outputFile, serialized, 0644)
-		if err != nil {
-			return fmt.Errorf("failed to write output to file '%s': %w", outputFile, err)
-		}
+		return nil
+	}
+}
+
+// nopCloser wraps stdout so that closing the output does not close it.
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// OpenWriter opens the specified output file, or stdout, for writing.
+func OpenWriter(outputFile string) (io.WriteCloser, error) {
+	if outputFile == "*stdout" {
+		return nopCloser{os.Stdout}, nil
+	}
+	f, err := os.OpenFile(outputFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output file '%s': %w", outputFile, err)
 	}
-	return nil
+	return f, nil
 }
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sequence is a sequence, or sample, of an EQL response.
+type sequence struct {
+	joinKeys []any
+	events   []any
+}
+
+// sequences returns the sequences, or samples, of an EQL response, returned
+// as its hits, or false if the results are not sequences.
+func sequences(results any) ([]sequence, bool) {
+	docs := documents(results)
+	if len(docs) == 0 {
+		return nil, false
+	}
+
+	seqs := make([]sequence, 0, len(docs))
+	for _, doc := range docs {
+		s, ok := doc.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		events, ok := s["events"].([]any)
+		if !ok {
+			return nil, false
+		}
+		keys, _ := s["join_keys"].([]any)
+		seqs = append(seqs, sequence{joinKeys: keys, events: events})
+	}
+	return seqs, true
+}
+
+// formatKeys formats the join keys of a sequence.
+func formatKeys(keys []any, sep, arrayDelimiter string) string {
+	cells := make([]string, len(keys))
+	for i, key := range keys {
+		cells[i] = FormatCell(key, arrayDelimiter)
+	}
+	return strings.Join(cells, sep)
+}
+
+// SequenceTables converts the sequences, or samples, of an EQL response,
+// returned as its hits, into one table of events each, named after their
+// position and join keys, e.g. "sequence 1 [web-1]". It returns nil if the
+// results are not sequences.
+func SequenceTables(results any, columns []string, arrayDelimiter string) []NamedTable {
+	seqs, ok := sequences(results)
+	if !ok {
+		return nil
+	}
+
+	tables := make([]NamedTable, 0, len(seqs))
+	for i, s := range seqs {
+		name := fmt.Sprintf("sequence %d", i+1)
+		if len(s.joinKeys) > 0 {
+			name += " [" + formatKeys(s.joinKeys, ", ", arrayDelimiter) + "]"
+		}
+		tables = append(tables, NamedTable{Name: name, Table: ToTable(s.events, columns)})
+	}
+	return tables
+}
+
+// SequenceTable converts the sequences, or samples, of an EQL response into
+// a single table of their events, led by the "sequence" position and the
+// "join_keys" of their sequence, so that the rows of csv and tsv output can be
+// told apart without a header. It returns false if the results are not
+// sequences.
+func SequenceTable(results any, columns []string, arrayDelimiter string) (Table, bool) {
+	seqs, ok := sequences(results)
+	if !ok {
+		return Table{}, false
+	}
+
+	if len(columns) == 0 {
+		var events []any
+		for _, s := range seqs {
+			events = append(events, s.events...)
+		}
+		columns = inferColumns(events)
+	}
+
+	table := Table{Columns: append([]string{"sequence", "join_keys"}, columns...)}
+	for i, s := range seqs {
+		keys := formatKeys(s.joinKeys, arrayDelimiter, arrayDelimiter)
+		for _, row := range ToTable(s.events, columns).Rows {
+			table.Rows = append(table.Rows, append([]any{i + 1, keys}, row...))
+		}
+	}
+	return table, true
+}
@@ -0,0 +1,82 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSequenceTables(t *testing.T) {
+	event := func(id, category, name string) map[string]any {
+		return map[string]any{"_id": id, "_source": map[string]any{"event": map[string]any{"category": category}, "process": map[string]any{"name": name}}}
+	}
+	response := map[string]any{
+		"took":  4.0,
+		"total": map[string]any{"value": 2.0, "relation": "eq"},
+		"hits": []any{
+			map[string]any{"join_keys": []any{"web-1"}, "events": []any{event("1", "process", "curl"), event("2", "network", "curl")}},
+			map[string]any{"events": []any{event("3", "process", "wget")}},
+		},
+	}
+
+	t.Run("Tables", func(t *testing.T) {
+		tables := SequenceTables(response, []string{"_id", "process.name"}, ";")
+		assert.Equal(t, []NamedTable{
+			{Name: "sequence 1 [web-1]", Table: Table{Columns: []string{"_id", "process.name"}, Rows: [][]any{{"1", "curl"}, {"2", "curl"}}}},
+			{Name: "sequence 2", Table: Table{Columns: []string{"_id", "process.name"}, Rows: [][]any{{"3", "wget"}}}},
+		}, tables)
+	})
+
+	t.Run("Not Sequences", func(t *testing.T) {
+		assert.Nil(t, SequenceTables(map[string]any{"took": 1.0, "hits": []any{event("1", "process", "curl")}}, nil, ";"))
+	})
+
+	t.Run("Table Format", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, NewTableFormatter([]string{"event.category", "process.name"}, 0, false, ";").Format(&buf, response))
+		assert.Equal(t, "sequence 1 [web-1]:\n"+
+			"event.category  process.name\n"+
+			"process         curl\n"+
+			"network         curl\n"+
+			"\n"+
+			"sequence 2:\n"+
+			"event.category  process.name\n"+
+			"process         wget\n"+
+			"(2 of 2 hits, took 4ms)\n", buf.String())
+	})
+
+	t.Run("CSV Format", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, NewCSVFormatter(',', []string{"_id", "process.name"}, true, ";").Format(&buf, response))
+		assert.Equal(t, "sequence,join_keys,_id,process.name\n1,web-1,1,curl\n1,web-1,2,curl\n2,,3,wget\n", buf.String())
+	})
+
+	t.Run("Single Table", func(t *testing.T) {
+		table, ok := SequenceTable(response, nil, ";")
+		require.True(t, ok)
+		assert.Equal(t, Table{
+			Columns: []string{"sequence", "join_keys", "event.category", "process.name"},
+			Rows: [][]any{
+				{1, "web-1", "process", "curl"},
+				{1, "web-1", "network", "curl"},
+				{2, "", "process", "wget"},
+			},
+		}, table)
+
+		multi := map[string]any{"hits": []any{map[string]any{"join_keys": []any{"web-1", 42.0}, "events": []any{event("1", "process", "curl")}}}}
+		table, ok = SequenceTable(multi, []string{"_id"}, "|")
+		require.True(t, ok)
+		assert.Equal(t, [][]any{{1, "web-1|42", "1"}}, table.Rows)
+
+		_, ok = SequenceTable(map[string]any{"hits": []any{event("1", "process", "curl")}}, nil, ";")
+		assert.False(t, ok)
+	})
+
+	t.Run("TSV Format without Header", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, NewCSVFormatter('\t', []string{"_id"}, false, ";").Format(&buf, response))
+		assert.Equal(t, "1\tweb-1\t1\n1\tweb-1\t2\n2\t\t3\n", buf.String())
+	})
+}
@@ -0,0 +1,55 @@
+package output
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// TotalHits returns the total hit count of a search response, e.g. "48,213",
+// or "10,000+" if the count is a lower bound. It reports false if the total
+// was not tracked.
+func TotalHits(response map[string]any) (string, bool) {
+	total, ok := response["total"].(map[string]any)
+	if !ok {
+		return "", false
+	}
+	value, ok := total["value"].(float64)
+	if !ok {
+		return "", false
+	}
+	count := FormatCount(int64(value))
+	if relation, _ := total["relation"].(string); relation == "gte" {
+		count += "+"
+	}
+	return count, true
+}
+
+// Summary describes how many of the matching hits a search response showed,
+// e.g. "showing 100 of 48,213 hits in 37ms".
+func Summary(response map[string]any, shown int) string {
+	summary := fmt.Sprintf("showing %s hits", FormatCount(int64(shown)))
+	if total, ok := TotalHits(response); ok {
+		summary = fmt.Sprintf("showing %s of %s hits", FormatCount(int64(shown)), total)
+	}
+	if took, ok := response["took"].(float64); ok {
+		summary += fmt.Sprintf(" in %dms", int(took))
+	}
+	return summary
+}
+
+// FormatCount formats n with thousands separators, e.g. "48,213".
+func FormatCount(n int64) string {
+	digits := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	out := make([]byte, 0, len(digits)+len(digits)/3)
+	for i := range len(digits) {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			out = append(out, ',')
+		}
+		out = append(out, digits[i])
+	}
+	return sign + string(out)
+}
@@ -0,0 +1,61 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummary(t *testing.T) {
+	testCases := []struct {
+		name     string
+		response map[string]any
+		shown    int
+		want     string
+	}{
+		{
+			name:     "Exact total",
+			response: map[string]any{"took": 37.0, "total": map[string]any{"value": 48213.0, "relation": "eq"}},
+			shown:    100,
+			want:     "showing 100 of 48,213 hits in 37ms",
+		},
+		{
+			name:     "Lower bound total",
+			response: map[string]any{"took": 5.0, "total": map[string]any{"value": 10000.0, "relation": "gte"}},
+			shown:    1500,
+			want:     "showing 1,500 of 10,000+ hits in 5ms",
+		},
+		{
+			name:     "Untracked total",
+			response: map[string]any{},
+			shown:    7,
+			want:     "showing 7 hits",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Summary(tc.response, tc.shown))
+		})
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	testCases := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1,000"},
+		{48213, "48,213"},
+		{1234567, "1,234,567"},
+		{-12345, "-12,345"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.want, func(t *testing.T) {
+			assert.Equal(t, tc.want, FormatCount(tc.n))
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Table is a tabular view of results, used by the csv, tsv and table formats.
+type Table struct {
+	Columns []string
+	Rows    [][]any
+}
+
+// NamedTable is a table written under its name, such as the tabular view of
+// one aggregation of a search response.
+type NamedTable struct {
+	Name string
+	Table
+}
+
+// ToTable converts results into one row per document. Search hits are
+// represented by their _source and the fields returned by the fields API, in
+// which the hit metadata such as _id and _index can also be selected. If no
+// columns are given, they are the columns of a tabular response, such as an
+// ES|QL response, or else inferred from the union of the flattened document
+// keys, metadata excluded.
+func ToTable(results any, columns []string) Table {
+	docs := documents(results)
+	records := make([]map[string]any, 0, len(docs))
+	for _, doc := range docs {
+		records = append(records, toRecord(doc))
+	}
+
+	if len(columns) == 0 {
+		columns = resultColumns(results)
+	}
+
+	rows := make([][]any, 0, len(records))
+	for _, record := range records {
+		row := make([]any, len(columns))
+		for i, col := range columns {
+			row[i], _ = Lookup(record, col)
+		}
+		rows = append(rows, row)
+	}
+	return Table{Columns: columns, Rows: rows}
+}
+
+// toRecord returns the map a row's cells are looked up in.
+func toRecord(doc any) map[string]any {
+	switch d := doc.(type) {
+	case map[string]any:
+		source, ok := d["_source"].(map[string]any)
+		if _, hasFields := d["fields"]; !ok && !hasFields {
+			return d
+		}
+		record := make(map[string]any, len(source)+4)
+		for k, v := range d {
+			if k != "_source" {
+				record[k] = v
+			}
+		}
+		for k, v := range source {
+			record[k] = v
+		}
+		mergeFields(record, d)
+		return record
+	default:
+		return map[string]any{"value": doc}
+	}
+}
+
+// mergeFields adds the values of the fields returned by the fields API, such
+// as runtime fields, to a record of a hit, unless its _source has them. Single
+// values are unwrapped from the arrays the fields API returns.
+func mergeFields(record map[string]any, hit map[string]any) {
+	fields, ok := hit["fields"].(map[string]any)
+	if !ok {
+		return
+	}
+	for k, v := range fields {
+		if _, found := Lookup(record, k); found {
+			continue
+		}
+		if values, ok := v.([]any); ok && len(values) == 1 {
+			v = values[0]
+		}
+		record[k] = v
+	}
+}
+
+// resultColumns returns the columns of results without selected columns: the
+// columns of a tabular response, or else the union of the document keys.
+func resultColumns(results any) []string {
+	if columns := responseColumns(results); len(columns) > 0 {
+		return columns
+	}
+	return inferColumns(documents(results))
+}
+
+// responseColumns returns the names of the "columns" of a tabular response,
+// such as an ES|QL response, in order.
+func responseColumns(results any) []string {
+	response, ok := results.(map[string]any)
+	if !ok {
+		return nil
+	}
+	columns, _ := response["columns"].([]any)
+	names := make([]string, 0, len(columns))
+	for _, c := range columns {
+		if column, ok := c.(map[string]any); ok {
+			if name, ok := column["name"].(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// inferColumns returns the sorted union of the flattened keys of all documents.
+func inferColumns(docs []any) []string {
+	seen := map[string]bool{}
+	for _, doc := range docs {
+		switch d := doc.(type) {
+		case map[string]any:
+			fields, hasFields := d["fields"].(map[string]any)
+			if source, ok := d["_source"].(map[string]any); ok {
+				d = source
+			} else if hasFields {
+				d = nil
+			}
+			flattenKeys(d, "", seen)
+			for k := range fields {
+				seen[k] = true
+			}
+		default:
+			seen["value"] = true
+		}
+	}
+
+	columns := make([]string, 0, len(seen))
+	for k := range seen {
+		columns = append(columns, k)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// flattenKeys collects the dotted paths of all non-object values of m.
+func flattenKeys(m map[string]any, prefix string, seen map[string]bool) {
+	for k, v := range m {
+		key := prefix + k
+		if nested, ok := v.(map[string]any); ok && len(nested) > 0 {
+			flattenKeys(nested, key+".", seen)
+			continue
+		}
+		seen[key] = true
+	}
+}
+
+// Lookup resolves a dotted path such as "user.name" in a document, whether the
+// document nests objects ({"user":{"name":...}}) or uses dotted keys
+// ({"user.name":...}).
+func Lookup(doc map[string]any, path string) (any, bool) {
+	if v, ok := doc[path]; ok {
+		return v, true
+	}
+	for i := 0; i < len(path); i++ {
+		if path[i] != '.' {
+			continue
+		}
+		if nested, ok := doc[path[:i]].(map[string]any); ok {
+			if v, found := Lookup(nested, path[i+1:]); found {
+				return v, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// FormatCell renders a cell value as text. Arrays are joined with
+// arrayDelimiter and objects are rendered as compact JSON.
+func FormatCell(v any, arrayDelimiter string) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	case []any:
+		parts := make([]string, len(val))
+		for i, item := range val {
+			parts[i] = FormatCell(item, arrayDelimiter)
+		}
+		return strings.Join(parts, arrayDelimiter)
+	case map[string]any:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprintf("%v", val)
+		}
+		return string(data)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
@@ -0,0 +1,96 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	doc := map[string]any{
+		"user":      map[string]any{"name": "bob", "geo": map[string]any{"city": "Oslo"}},
+		"host.name": "web-1",
+	}
+
+	testCases := []struct {
+		path      string
+		want      any
+		wantFound bool
+	}{
+		{"user.name", "bob", true},
+		{"user.geo.city", "Oslo", true},
+		{"host.name", "web-1", true},
+		{"user.missing", nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			got, found := Lookup(doc, tc.path)
+			assert.Equal(t, tc.wantFound, found)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestToTable_Fields(t *testing.T) {
+	response := map[string]any{
+		"took": 1.0,
+		"hits": []any{
+			map[string]any{
+				"_id":     "1",
+				"_source": map[string]any{"user": map[string]any{"name": "bob"}},
+				"fields":  map[string]any{"user.name": []any{"bob"}, "duration_ms": []any{12.0}, "tags": []any{"a", "b"}},
+			},
+			map[string]any{
+				"_id":    "2",
+				"fields": map[string]any{"duration_ms": []any{7.0}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name    string
+		columns []string
+		want    Table
+	}{
+		{
+			name: "Inferred columns",
+			want: Table{
+				Columns: []string{"duration_ms", "tags", "user.name"},
+				Rows:    [][]any{{12.0, []any{"a", "b"}, "bob"}, {7.0, nil, nil}},
+			},
+		},
+		{
+			name:    "Selected columns",
+			columns: []string{"_id", "duration_ms"},
+			want: Table{
+				Columns: []string{"_id", "duration_ms"},
+				Rows:    [][]any{{"1", 12.0}, {"2", 7.0}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ToTable(response, tc.columns))
+		})
+	}
+}
+
+func TestToTable_Columns(t *testing.T) {
+	response := map[string]any{
+		"took":    4.0,
+		"columns": []any{map[string]any{"name": "host.name", "type": "keyword"}, map[string]any{"name": "count", "type": "long"}},
+		"hits":    []any{map[string]any{"host.name": "web-1", "count": 12.0}, map[string]any{"host.name": "web-2", "count": nil}},
+	}
+
+	assert.Equal(t, Table{
+		Columns: []string{"host.name", "count"},
+		Rows:    [][]any{{"web-1", 12.0}, {"web-2", nil}},
+	}, ToTable(response, nil))
+
+	assert.Equal(t, Table{
+		Columns: []string{"count"},
+		Rows:    [][]any{{12.0}, {nil}},
+	}, ToTable(response, []string{"count"}))
+}
@@ -0,0 +1,262 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	columnGap      = "  "
+	minColumnWidth = 3
+	ellipsis       = "…"
+	headerStyle    = "\x1b[1;4m"
+	resetStyle     = "\x1b[0m"
+)
+
+// tableFormatter writes results as an aligned, human-readable table.
+type tableFormatter struct {
+	fields         []string
+	width          int
+	color          bool
+	arrayDelimiter string
+
+	// columns and widths are those of the pages of a paginated search, set by
+	// the first page written, or a later one adding columns.
+	columns []string
+	widths  []int
+}
+
+// NewTableFormatter returns a formatter writing one aligned row per document
+// with the given fields as columns, or columns inferred from the results.
+// Cells are truncated so that rows fit in width, unless width is 0. The header
+// is highlighted if color is set. The aggregations of search responses, and
+// the sequences of EQL responses, are written as one table each, followed by a
+// footer summarizing the request. The pages of a paginated search are written
+// as they come, as a single table with a single footer. It has the columns and
+// column widths of the first page, which the cells of later pages overflow
+// unless width is set; without fields, a page adding columns repeats the
+// header, with the new columns appended.
+func NewTableFormatter(fields []string, width int, color bool, arrayDelimiter string) Formatter {
+	return &tableFormatter{
+		fields:         fields,
+		width:          width,
+		color:          color,
+		arrayDelimiter: arrayDelimiter,
+	}
+}
+
+func (f *tableFormatter) Format(w io.Writer, results any) error {
+	table := ToTable(results, f.fields)
+	rows := len(table.Rows)
+	named := AggregationTables(results)
+	if sequences := SequenceTables(results, f.fields, f.arrayDelimiter); sequences != nil {
+		table, named = Table{}, sequences
+		rows = len(sequences)
+	}
+
+	var sb strings.Builder
+	f.writeTable(&sb, table)
+	for _, t := range named {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(t.Name + ":\n")
+		f.writeTable(&sb, t.Table)
+	}
+	if response, ok := results.(map[string]any); ok && isSearchResponse(response) {
+		sb.WriteString(footer(response, rows))
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// FormatPage writes the rows of a page, after the header if it is the first
+// page or adds columns.
+func (f *tableFormatter) FormatPage(w io.Writer, page any) error {
+	columns := f.columns
+	switch {
+	case columns == nil && f.fields != nil:
+		columns = f.fields
+	case columns == nil:
+		columns = resultColumns(page)
+	case f.fields == nil:
+		columns = append(slices.Clip(columns), newColumns(columns, resultColumns(page))...)
+	}
+
+	table := ToTable(page, columns)
+	if len(table.Rows) == 0 {
+		return nil
+	}
+	cells := f.cells(table)
+	var sb strings.Builder
+	if len(columns) != len(f.columns) {
+		if f.columns != nil {
+			sb.WriteString("\n")
+		}
+		f.columns = columns
+		f.widths = f.columnWidths(columns, cells)
+		f.writeRow(&sb, columns, f.widths, f.color)
+	}
+	for _, row := range cells {
+		widths := f.widths
+		if f.width <= 0 {
+			// without a width to fit, cells wider than those of the first
+			// page overflow their column instead of being cut
+			widths = widened(f.widths, row)
+		}
+		f.writeRow(&sb, row, widths, false)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// Finish writes the footer of all pages.
+func (f *tableFormatter) Finish(w io.Writer, summary map[string]any, shown int) error {
+	_, err := io.WriteString(w, footer(summary, shown))
+	return err
+}
+
+// writeTable writes the header and rows of a table, if it has rows.
+func (f *tableFormatter) writeTable(sb *strings.Builder, table Table) {
+	if len(table.Rows) == 0 {
+		return
+	}
+	cells := f.cells(table)
+	widths := f.columnWidths(table.Columns, cells)
+	f.writeRow(sb, table.Columns, widths, f.color)
+	for _, row := range cells {
+		f.writeRow(sb, row, widths, false)
+	}
+}
+
+// cells renders the cells of a table as single lines of text.
+func (f *tableFormatter) cells(table Table) [][]string {
+	cells := make([][]string, len(table.Rows))
+	for i, row := range table.Rows {
+		cells[i] = make([]string, len(row))
+		for j, cell := range row {
+			cells[i][j] = singleLine(FormatCell(cell, f.arrayDelimiter))
+		}
+	}
+	return cells
+}
+
+// columnWidths returns the width of each column, shrinking the widest columns
+// until the row fits in the formatter width.
+func (f *tableFormatter) columnWidths(columns []string, rows [][]string) []int {
+	widths := make([]int, len(columns))
+	for i, col := range columns {
+		widths[i] = utf8.RuneCountInString(col)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	if f.width <= 0 {
+		return widths
+	}
+	available := f.width - len(columnGap)*(len(columns)-1)
+	if sum(widths) <= available {
+		return widths
+	}
+
+	// find the largest cap on column widths that fits the available width
+	lo, hi := minColumnWidth, 0
+	for _, w := range widths {
+		hi = max(hi, w)
+	}
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if sum(capped(widths, mid)) <= available {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return capped(widths, lo)
+}
+
+func (f *tableFormatter) writeRow(sb *strings.Builder, cells []string, widths []int, highlight bool) {
+	for i, cell := range cells {
+		if i > 0 {
+			sb.WriteString(columnGap)
+		}
+		cell = truncate(cell, widths[i])
+		padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+		if i == len(cells)-1 {
+			padding = "" // no trailing whitespace
+		}
+		if highlight {
+			cell = headerStyle + cell + resetStyle
+		}
+		sb.WriteString(cell)
+		sb.WriteString(padding)
+	}
+	sb.WriteString("\n")
+}
+
+// footer summarizes a search response: the number of hits shown out of the
+// total, its duration and shard failures.
+func footer(response map[string]any, rows int) string {
+	parts := []string{fmt.Sprintf("%s hits", FormatCount(int64(rows)))}
+	if total, ok := TotalHits(response); ok {
+		parts[0] = fmt.Sprintf("%s of %s hits", FormatCount(int64(rows)), total)
+	}
+	if took, ok := response["took"].(float64); ok {
+		parts = append(parts, fmt.Sprintf("took %dms", int(took)))
+	}
+	if shards, ok := response["_shards"].(map[string]any); ok {
+		failed, _ := shards["failed"].(float64)
+		total, _ := shards["total"].(float64)
+		parts = append(parts, fmt.Sprintf("%d/%d shards failed", int(failed), int(total)))
+	}
+	if timedOut, _ := response["timed_out"].(bool); timedOut {
+		parts = append(parts, "timed out")
+	}
+	return "(" + strings.Join(parts, ", ") + ")\n"
+}
+
+// singleLine replaces line breaks and tabs, which would break the alignment.
+func singleLine(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ").Replace(s)
+}
+
+// truncate shortens s to width runes, marking the cut with an ellipsis.
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + ellipsis
+}
+
+// widened returns the widths of the columns, widened to fit the cells of row.
+func widened(widths []int, row []string) []int {
+	out := make([]int, len(widths))
+	for i, w := range widths {
+		out[i] = max(w, utf8.RuneCountInString(row[i]))
+	}
+	return out
+}
+
+func capped(widths []int, limit int) []int {
+	out := make([]int, len(widths))
+	for i, w := range widths {
+		out[i] = min(w, limit)
+	}
+	return out
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
@@ -0,0 +1,178 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableFormatter(t *testing.T) {
+	response := map[string]any{
+		"took":      37.0,
+		"timed_out": false,
+		"_shards":   map[string]any{"total": 2.0, "failed": 1.0},
+		"hits": []any{
+			map[string]any{"_id": "1", "_source": map[string]any{"level": "info", "message": "service started on port 8080"}},
+			map[string]any{"_id": "2", "_source": map[string]any{"level": "error", "message": "line one\nline two"}},
+		},
+	}
+
+	testCases := []struct {
+		name    string
+		fields  []string
+		width   int
+		color   bool
+		results any
+		want    string
+	}{
+		{
+			name:    "Inferred columns",
+			results: response,
+			want: "level  message\n" +
+				"info   service started on port 8080\n" +
+				"error  line one line two\n" +
+				"(2 hits, took 37ms, 1/2 shards failed)\n",
+		},
+		{
+			name:    "Truncated to width",
+			fields:  []string{"_id", "message"},
+			width:   18,
+			results: response,
+			want: "_id  message\n" +
+				"1    service star…\n" +
+				"2    line one lin…\n" +
+				"(2 hits, took 37ms, 1/2 shards failed)\n",
+		},
+		{
+			name:   "Total hits",
+			fields: []string{"_id"},
+			results: map[string]any{
+				"took":  2.0,
+				"total": map[string]any{"value": 10000.0, "relation": "gte"},
+				"hits":  []any{map[string]any{"_id": "1"}},
+			},
+			want: "_id\n1\n(1 of 10,000+ hits, took 2ms)\n",
+		},
+		{
+			name:    "Colored header",
+			fields:  []string{"_id"},
+			color:   true,
+			results: []any{map[string]any{"_id": "1"}},
+			want:    "\x1b[1;4m_id\x1b[0m\n1\n",
+		},
+		{
+			name: "Aggregations",
+			results: map[string]any{
+				"took": 5.0,
+				"aggregations": map[string]any{
+					"terms_level": map[string]any{"buckets": []any{
+						map[string]any{"key": "info", "doc_count": 120.0},
+						map[string]any{"key": "error", "doc_count": 3.0},
+					}},
+					"cardinality_host": map[string]any{"value": 4.0},
+				},
+			},
+			want: "cardinality_host:\n" +
+				"value\n" +
+				"4\n" +
+				"\n" +
+				"terms_level:\n" +
+				"key    doc_count\n" +
+				"info   120\n" +
+				"error  3\n" +
+				"(0 hits, took 5ms)\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := NewTableFormatter(tc.fields, tc.width, tc.color, ";").Format(&buf, tc.results)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, buf.String())
+		})
+	}
+}
+
+func TestTableFormatter_Pages(t *testing.T) {
+	page := func(sources ...map[string]any) map[string]any {
+		hits := make([]any, len(sources))
+		for i, s := range sources {
+			hits[i] = map[string]any{"_id": s["id"], "_source": s}
+		}
+		return map[string]any{"took": 2.0, "total": map[string]any{"value": 3.0, "relation": "eq"}, "hits": hits}
+	}
+	summary := map[string]any{"took": 4.0, "total": map[string]any{"value": 3.0, "relation": "eq"}}
+
+	testCases := []struct {
+		name   string
+		fields []string
+		width  int
+		pages  []any
+		shown  int
+		want   string
+	}{
+		{
+			name:   "Widths of the First Page",
+			fields: []string{"_id", "message", "level"},
+			pages: []any{
+				page(map[string]any{"id": "1", "message": "short", "level": "info"}, map[string]any{"id": "2", "message": "tiny", "level": "warn"}),
+				page(map[string]any{"id": "10", "message": "a much longer message", "level": "error"}),
+			},
+			shown: 3,
+			want: "_id  message  level\n" +
+				"1    short    info\n" +
+				"2    tiny     warn\n" +
+				"10   a much longer message  error\n" +
+				"(3 of 3 hits, took 4ms)\n",
+		},
+		{
+			name:   "Cut to the Width of the First Page",
+			fields: []string{"_id", "message"},
+			width:  40,
+			pages: []any{
+				page(map[string]any{"id": "1", "message": "short"}, map[string]any{"id": "2", "message": "tiny"}),
+				page(map[string]any{"id": "10", "message": "a much longer message"}),
+			},
+			shown: 3,
+			want: "_id  message\n" +
+				"1    short\n" +
+				"2    tiny\n" +
+				"10   a much…\n" +
+				"(3 of 3 hits, took 4ms)\n",
+		},
+		{
+			name: "Columns Added by a Page",
+			pages: []any{
+				page(map[string]any{"level": "info"}, map[string]any{"level": "warn"}),
+				page(map[string]any{"level": "error", "host": "web-1"}),
+			},
+			shown: 3,
+			want: "level\n" +
+				"info\n" +
+				"warn\n" +
+				"\n" +
+				"level  host\n" +
+				"error  web-1\n" +
+				"(3 of 3 hits, took 4ms)\n",
+		},
+		{
+			name: "No Hits",
+			want: "(0 of 3 hits, took 4ms)\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			f := NewTableFormatter(tc.fields, tc.width, false, ";").(PageFormatter)
+			for _, p := range tc.pages {
+				require.NoError(t, f.FormatPage(&buf, p))
+			}
+			require.NoError(t, f.Finish(&buf, summary, tc.shown))
+			assert.Equal(t, tc.want, buf.String())
+		})
+	}
+}
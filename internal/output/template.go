@@ -0,0 +1,160 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ansiColors maps the color names accepted by the template color function to ANSI codes.
+var ansiColors = map[string]string{
+	"black":   "30",
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+	"white":   "37",
+	"gray":    "90",
+	"bold":    "1",
+}
+
+// ParseTemplate parses a Go text/template with the helper functions available
+// to output templates. The color function only emits escape codes if color is set.
+func ParseTemplate(text string, color bool) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"json":     toJSON,
+		"toYaml":   toYAML,
+		"date":     formatDate,
+		"get":      get,
+		"truncate": truncateValue,
+		"default":  defaultValue,
+		"color": func(name string, v any) (string, error) {
+			code, ok := ansiColors[name]
+			if !ok {
+				return "", fmt.Errorf("unknown color '%s'", name)
+			}
+			if !color {
+				return toString(v), nil
+			}
+			return "\x1b[" + code + "m" + toString(v) + resetStyle, nil
+		},
+	}
+	return template.New("output").Funcs(funcs).Parse(text)
+}
+
+// templateFormatter renders results through a template.
+type templateFormatter struct {
+	tmpl        *template.Template
+	perResponse bool
+}
+
+// NewTemplateFormatter returns a formatter executing tmpl once per document,
+// with the hit's _source merged with its metadata (_id, _index, ...) as data,
+// or once per result set if perResponse is set. A newline is added after each
+// rendering unless the template ends with one.
+func NewTemplateFormatter(tmpl *template.Template, perResponse bool) Formatter {
+	return &templateFormatter{tmpl: tmpl, perResponse: perResponse}
+}
+
+func (f *templateFormatter) Format(w io.Writer, results any) error {
+	if f.perResponse {
+		return f.render(w, results)
+	}
+	for _, doc := range documents(results) {
+		if err := f.render(w, toRecord(doc)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *templateFormatter) render(w io.Writer, data any) error {
+	var sb strings.Builder
+	if err := f.tmpl.Execute(&sb, data); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	out := sb.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	_, err := io.WriteString(w, out)
+	return err
+}
+
+// toJSON renders v as compact JSON.
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// toYAML renders v as YAML.
+func toYAML(v any) (string, error) {
+	data, err := yaml.Marshal(v)
+	return strings.TrimSuffix(string(data), "\n"), err
+}
+
+// formatDate formats an RFC 3339 timestamp or epoch milliseconds with a Go time layout.
+func formatDate(layout string, v any) (string, error) {
+	var t time.Time
+	switch val := v.(type) {
+	case time.Time:
+		t = val
+	case float64:
+		t = time.UnixMilli(int64(val)).UTC()
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, val)
+		if err != nil {
+			return "", fmt.Errorf("date: %w", err)
+		}
+		t = parsed
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("date: unsupported value %v", v)
+	}
+	return t.Format(layout), nil
+}
+
+// get resolves a dotted path in a document, returning nil if it is missing.
+func get(path string, doc any) any {
+	m, ok := doc.(map[string]any)
+	if !ok {
+		return nil
+	}
+	v, _ := Lookup(m, path)
+	return v
+}
+
+// truncateValue shortens the text of v to at most n characters.
+func truncateValue(n int, v any) string {
+	s := toString(v)
+	if n <= 0 {
+		return ""
+	}
+	return truncate(s, n)
+}
+
+// defaultValue returns def if v is missing or empty.
+func defaultValue(def any, v any) any {
+	switch val := v.(type) {
+	case nil:
+		return def
+	case string:
+		if val == "" {
+			return def
+		}
+	}
+	return v
+}
+
+// toString renders a value the way a table cell is rendered.
+func toString(v any) string {
+	return FormatCell(v, ",")
+}
@@ -0,0 +1,81 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateFormatter(t *testing.T) {
+	response := map[string]any{
+		"took": 5.0,
+		"hits": []any{
+			map[string]any{"_id": "1", "_source": map[string]any{
+				"timestamp": "2025-03-01T10:15:00Z",
+				"level":     "error",
+				"message":   "connection refused by upstream",
+				"user":      map[string]any{"name": "bob"},
+			}},
+			map[string]any{"_id": "2", "_source": map[string]any{
+				"timestamp": 1740824100000.0,
+				"level":     "",
+				"message":   "ok",
+			}},
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		template    string
+		color       bool
+		perResponse bool
+		want        string
+	}{
+		{
+			name:     "Fields and metadata",
+			template: "{{._id}} {{.level}}",
+			want:     "1 error\n2 \n",
+		},
+		{
+			name:     "Helpers",
+			template: `{{.timestamp | date "15:04"}} {{.level | default "info"}} {{.message | truncate 10}} {{get "user.name" . | default "-"}}`,
+			want:     "10:15 error connectio… bob\n10:15 info ok -\n",
+		},
+		{
+			name:     "JSON and YAML",
+			template: "{{json .user}}|{{toYaml .user}}",
+			want:     "{\"name\":\"bob\"}|name: bob\nnull|null\n",
+		},
+		{
+			name:     "Color",
+			template: `{{.level | color "red"}}`,
+			color:    true,
+			want:     "\x1b[31merror\x1b[0m\n\x1b[31m\x1b[0m\n",
+		},
+		{
+			name:     "Color disabled",
+			template: `{{.level | color "red"}}`,
+			want:     "error\n\n",
+		},
+		{
+			name:        "Per response",
+			template:    "took {{.took}}ms, {{len .hits}} hits",
+			perResponse: true,
+			want:        "took 5ms, 2 hits\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl, err := ParseTemplate(tc.template, tc.color)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			err = NewTemplateFormatter(tmpl, tc.perResponse).Format(&buf, response)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, buf.String())
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package output
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// IsTerminal reports whether f is an interactive terminal.
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// TerminalWidth returns the width of the terminal f, or 0 if f is not a terminal.
+func TerminalWidth(f *os.File) int {
+	if !IsTerminal(f) {
+		return 0
+	}
+	width, _, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// UseColor reports whether output written to f should be colored, honoring
+// the NO_COLOR convention (https://no-color.org).
+func UseColor(f *os.File) bool {
+	_, noColor := os.LookupEnv("NO_COLOR")
+	return !noColor && IsTerminal(f)
+}
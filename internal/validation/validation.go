@@ -1,16 +1,24 @@
 package validation
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/fa7ad/esq/internal/kql"
 	"github.com/fa7ad/esq/internal/options"
+	"github.com/fa7ad/esq/internal/output"
 	"github.com/itchyny/gojq"
 )
 
+// sha256Hex matches a SHA-256 digest in hex.
+var sha256Hex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
 // ValidateCliArgs validates the command-line arguments.
 func ValidateCliArgs(args options.CliArgs) error {
 	err := ValidateElasticOptions(args.ElasticOptions)
@@ -31,6 +39,16 @@ func ValidateCliArgs(args options.CliArgs) error {
 	if err != nil {
 		return fmt.Errorf("error validating output options: %w", err)
 	}
+	if args.SQLTranslate {
+		if err := validateSQLTranslateOutput(args.OutputOptions); err != nil {
+			return fmt.Errorf("error validating output options: %w", err)
+		}
+	}
+	if args.Paginate() {
+		if err := validatePagedOutput(args.OutputOptions); err != nil {
+			return fmt.Errorf("error validating output options: %w", err)
+		}
+	}
 
 	return nil
 }
@@ -46,8 +64,8 @@ func getKeys(m map[string]bool) []string {
 
 // ValidateQueryOptions validates the query options.
 func ValidateQueryOptions(queryOptions options.QueryOptions) error {
-	if queryOptions.KQL == "" && queryOptions.DSL == "" && queryOptions.Lucene == "" && queryOptions.QueryFile == "" {
-		return fmt.Errorf("one of --kql, --dsl, --lucene, or --query-file must be provided")
+	if !queryOptions.HasQuery() && !queryOptions.HasAggregations() && !queryOptions.HasFilters() && !queryOptions.Count {
+		return fmt.Errorf("one of --kql, --dsl, --lucene, --query-file, --esql, --eql or --sql must be provided")
 	}
 
 	if queryOptions.QueryFile != "" && (queryOptions.KQL+queryOptions.DSL+queryOptions.Lucene != "") {
@@ -60,6 +78,12 @@ func ValidateQueryOptions(queryOptions options.QueryOptions) error {
 		}
 	}
 
+	if queryOptions.KQL != "" {
+		if _, err := kql.Parse(queryOptions.KQL); err != nil {
+			return fmt.Errorf("invalid KQL query: %w", err)
+		}
+	}
+
 	if queryOptions.DSL != "" {
 		if !json.Valid([]byte(queryOptions.DSL)) {
 			return fmt.Errorf("DSL must be a valid JSON string")
@@ -70,6 +94,30 @@ func ValidateQueryOptions(queryOptions options.QueryOptions) error {
 		return fmt.Errorf("only one of --kql, --dsl, or --lucene can be provided at a time")
 	}
 
+	if queryOptions.ESQL != "" {
+		if err := validateESQLOptions(queryOptions); err != nil {
+			return err
+		}
+	} else if len(queryOptions.ESQLParams) > 0 {
+		return fmt.Errorf("--esql-params requires --esql")
+	}
+
+	if queryOptions.EQL != "" {
+		if err := validateEQLOptions(queryOptions); err != nil {
+			return err
+		}
+	} else if queryOptions.HasEQLOptions() {
+		return fmt.Errorf("--timestamp-field, --event-category-field and --tiebreaker-field require --eql")
+	}
+
+	if queryOptions.SQL != "" {
+		if err := validateSQLOptions(queryOptions); err != nil {
+			return err
+		}
+	} else if queryOptions.SQLTranslate {
+		return fmt.Errorf("--sql-translate requires --sql")
+	}
+
 	if queryOptions.From != "" && !strings.HasPrefix(queryOptions.From, "now") {
 		if _, err := time.Parse(time.RFC3339, queryOptions.From); err != nil {
 			return fmt.Errorf("invalid --from timestamp: %v", err)
@@ -81,13 +129,109 @@ func ValidateQueryOptions(queryOptions options.QueryOptions) error {
 		}
 	}
 
+	if queryOptions.Limit < 0 {
+		return fmt.Errorf("--limit must not be negative")
+	}
+	if queryOptions.Paginate() && queryOptions.Size <= 0 {
+		return fmt.Errorf("--size must be positive when paging with --all or --limit")
+	}
+
+	if queryOptions.Count && (queryOptions.Paginate() || queryOptions.HasAggregations()) {
+		return fmt.Errorf("--count cannot be used with --all, --limit or aggregations")
+	}
+
+	if queryOptions.HasAggregations() {
+		if queryOptions.Paginate() {
+			return fmt.Errorf("aggregations cannot be used with --all or --limit")
+		}
+		if _, err := queryOptions.Aggregations(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := queryOptions.SortCombinations(); err != nil {
+		return err
+	}
+	if _, _, err := queryOptions.FilterClauses(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateESQLOptions checks that an ES|QL query is not combined with other
+// query languages, or options of searches.
+func validateESQLOptions(queryOptions options.QueryOptions) error {
+	if queryOptions.KQL+queryOptions.DSL+queryOptions.Lucene+queryOptions.QueryFile+queryOptions.EQL != "" {
+		return fmt.Errorf("--esql cannot be used with --kql, --dsl, --lucene, --query-file or --eql")
+	}
+	if queryOptions.Paginate() || queryOptions.Count || queryOptions.HasAggregations() {
+		return fmt.Errorf("--esql cannot be used with --all, --limit, --count or aggregations, use LIMIT and STATS instead")
+	}
+	f := queryOptions.FieldOptions
+	if len(f.SourceIncludes)+len(f.SourceExcludes)+len(f.DocvalueFields)+len(f.Sort) > 0 {
+		return fmt.Errorf("--esql cannot be used with --source-includes, --source-excludes, --docvalue-fields or --sort, use KEEP, DROP and SORT instead")
+	}
+	return nil
+}
+
+// validateEQLOptions checks that an EQL query is not combined with other query
+// languages, or options of searches it does not support.
+func validateEQLOptions(queryOptions options.QueryOptions) error {
+	if queryOptions.KQL+queryOptions.DSL+queryOptions.Lucene+queryOptions.QueryFile != "" {
+		return fmt.Errorf("--eql cannot be used with --kql, --dsl, --lucene or --query-file")
+	}
+	if queryOptions.Paginate() || queryOptions.Count || queryOptions.HasAggregations() {
+		return fmt.Errorf("--eql cannot be used with --all, --limit, --count or aggregations")
+	}
+	f := queryOptions.FieldOptions
+	if len(f.SourceIncludes)+len(f.SourceExcludes)+len(f.DocvalueFields)+len(f.Sort) > 0 {
+		return fmt.Errorf("--eql cannot be used with --source-includes, --source-excludes, --docvalue-fields or --sort")
+	}
+	return nil
+}
+
+// validateSQLOptions checks that an SQL query is not combined with other query
+// languages, or options of searches.
+func validateSQLOptions(queryOptions options.QueryOptions) error {
+	if queryOptions.KQL+queryOptions.DSL+queryOptions.Lucene+queryOptions.QueryFile+queryOptions.ESQL+queryOptions.EQL != "" {
+		return fmt.Errorf("--sql cannot be used with --kql, --dsl, --lucene, --query-file, --esql or --eql")
+	}
+	if queryOptions.Paginate() || queryOptions.Count || queryOptions.HasAggregations() {
+		return fmt.Errorf("--sql cannot be used with --all, --limit, --count or aggregations, use LIMIT and GROUP BY instead")
+	}
+	f := queryOptions.FieldOptions
+	if len(f.SourceIncludes)+len(f.SourceExcludes)+len(f.DocvalueFields)+len(f.Sort) > 0 {
+		return fmt.Errorf("--sql cannot be used with --source-includes, --source-excludes, --docvalue-fields or --sort, use SELECT and ORDER BY instead")
+	}
+	return nil
+}
+
+// validateSQLTranslateOutput checks that the translation of an SQL query, a
+// search request, is written as JSON rather than rendered like hits.
+func validateSQLTranslateOutput(outputOptions options.OutputOptions) error {
+	if outputOptions.Template != "" || outputOptions.TemplateFile != "" {
+		return fmt.Errorf("--sql-translate writes the search as JSON, it cannot be used with --template or --template-file")
+	}
+	if outputOptions.Output != "json" {
+		return fmt.Errorf("--sql-translate writes the search as JSON, it cannot be used with -o %s", outputOptions.Output)
+	}
+	return nil
+}
+
+// validatePagedOutput checks that the columns of csv and tsv output, written
+// page by page with --all or --limit, are known before the first page.
+func validatePagedOutput(outputOptions options.OutputOptions) error {
+	if (outputOptions.Output == "csv" || outputOptions.Output == "tsv") && len(outputOptions.Fields) == 0 {
+		return fmt.Errorf("-o %s with --all or --limit requires --fields, the columns of every page", outputOptions.Output)
+	}
 	return nil
 }
 
 // ValidateOutputOptions validates the output options.
 func ValidateOutputOptions(outputOptions options.OutputOptions) error {
 	// check if format is valid
-	validOutputs := map[string]bool{"json": true, "text": true}
+	validOutputs := map[string]bool{"json": true, "text": true, "ndjson": true, "csv": true, "tsv": true, "table": true, "template": true}
 	if _, ok := validOutputs[outputOptions.Output]; !ok {
 		return fmt.Errorf("invalid output format '%s'. Must be one of: %s", outputOptions.Output, strings.Join(getKeys(validOutputs), ", "))
 	}
@@ -106,17 +250,119 @@ func ValidateOutputOptions(outputOptions options.OutputOptions) error {
 		}
 	}
 
+	if outputOptions.Template != "" && outputOptions.TemplateFile != "" {
+		return fmt.Errorf("--template cannot be used with --template-file")
+	}
+	hasTemplate := outputOptions.Template != "" || outputOptions.TemplateFile != ""
+	if outputOptions.Output == "template" {
+		if !hasTemplate {
+			return fmt.Errorf("the template output format requires --template or --template-file")
+		}
+		text, err := outputOptions.TemplateText()
+		if err != nil {
+			return err
+		}
+		if _, err := output.ParseTemplate(text, false); err != nil {
+			return fmt.Errorf("invalid template: %s", err)
+		}
+	} else if hasTemplate {
+		return fmt.Errorf("--template and --template-file can only be used with the template output format")
+	}
+
+	return nil
+}
+
+// ValidateExportOptions validates the export options.
+func ValidateExportOptions(exportOptions options.ExportOptions, outputOptions options.OutputOptions) error {
+	if exportOptions.Slices < 1 {
+		return fmt.Errorf("--slices must be at least 1")
+	}
+
+	if exportOptions.Workers < 1 {
+		return fmt.Errorf("--workers must be at least 1")
+	}
+
+	if exportOptions.PerSlice {
+		if outputOptions.OutputFile == "" {
+			return fmt.Errorf("--per-slice requires --output-file")
+		}
+		for i := range exportOptions.Slices {
+			sliceFile := exportOptions.SliceFile(outputOptions.OutputFile, i)
+			if _, err := os.Stat(sliceFile); err == nil {
+				return fmt.Errorf("output file already exists: %s", sliceFile)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateTailOptions validates the tail options.
+func ValidateTailOptions(tailOptions options.TailOptions, outputOptions options.OutputOptions) error {
+	if tailOptions.Interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	// the header of csv and tsv output is written before the first poll
+	if tailOptions.Follow && (outputOptions.Output == "csv" || outputOptions.Output == "tsv") && len(outputOptions.Fields) == 0 {
+		return fmt.Errorf("--follow with -o %s requires --fields, the columns of every poll", outputOptions.Output)
+	}
+
 	return nil
 }
 
 // ValidateAuthOptions validates the authentication options.
 func ValidateAuthOptions(authOptions options.AuthOptions) error {
-	if authOptions.APIKey != "" && (authOptions.Username != "" || authOptions.Password != "") {
-		return fmt.Errorf("--api-key cannot be used with --username or --password")
+	passwordSources := []string{}
+	apiKeySources := []string{}
+	if authOptions.Password != "" {
+		passwordSources = append(passwordSources, "--password")
+	}
+	if authOptions.PasswordStdin {
+		passwordSources = append(passwordSources, "--password-stdin")
+	}
+	if authOptions.PasswordFile != "" {
+		passwordSources = append(passwordSources, "--password-file")
+	}
+	if authOptions.APIKey != "" {
+		apiKeySources = append(apiKeySources, "--api-key")
+	}
+	if authOptions.APIKeyFile != "" {
+		apiKeySources = append(apiKeySources, "--api-key-file")
+	}
+	if authOptions.CredentialCommand != "" {
+		// the credential command provides the password of --username, or the API key
+		if authOptions.Username != "" {
+			passwordSources = append(passwordSources, "--credential-command")
+		} else {
+			apiKeySources = append(apiKeySources, "--credential-command")
+		}
+	}
+	if len(passwordSources) > 1 {
+		return fmt.Errorf("only one password source can be used, got %s", strings.Join(passwordSources, " and "))
+	}
+	if len(apiKeySources) > 1 {
+		return fmt.Errorf("only one API key source can be used, got %s", strings.Join(apiKeySources, " and "))
 	}
 
-	if authOptions.Password != "" && authOptions.Username == "" {
-		return fmt.Errorf("--password must be used with --username")
+	methods := []string{}
+	if len(apiKeySources) > 0 {
+		methods = append(methods, strings.Join(apiKeySources, "/"))
+	}
+	if authOptions.Username != "" || len(passwordSources) > 0 {
+		methods = append(methods, "--username/--password")
+	}
+	if authOptions.BearerToken != "" {
+		methods = append(methods, "--bearer-token")
+	}
+	if authOptions.ServiceToken != "" {
+		methods = append(methods, "--service-token")
+	}
+	if len(methods) > 1 {
+		return fmt.Errorf("only one authentication method can be used, got %s", strings.Join(methods, " and "))
+	}
+
+	if len(passwordSources) > 0 && authOptions.Username == "" {
+		return fmt.Errorf("%s must be used with --username", passwordSources[0])
 	}
 
 	return nil
@@ -124,13 +370,109 @@ func ValidateAuthOptions(authOptions options.AuthOptions) error {
 
 // ValidateElasticOptions validates the Elasticsearch options.
 func ValidateElasticOptions(elasticOptions options.ElasticOptions) error {
-	if elasticOptions.Node == "" {
-		return fmt.Errorf("--node must be provided")
+	if len(elasticOptions.Nodes) == 0 && elasticOptions.CloudID == "" {
+		return fmt.Errorf("--node or --cloud-id must be provided")
+	}
+	if len(elasticOptions.Nodes) > 0 && elasticOptions.CloudID != "" {
+		return fmt.Errorf("--node cannot be used with --cloud-id")
+	}
+	if elasticOptions.CloudID != "" {
+		if err := validateCloudID(elasticOptions.CloudID); err != nil {
+			return err
+		}
+		if elasticOptions.Sniff {
+			return fmt.Errorf("--sniff cannot be used with --cloud-id")
+		}
+	}
+	for _, node := range elasticOptions.Nodes {
+		u, err := url.Parse(node)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid --node '%s', expected a URL like http://localhost:9200", node)
+		}
 	}
 
-	if elasticOptions.Index == "" {
+	// ES|QL and SQL queries name their indices in their FROM clause
+	if elasticOptions.Index == "" && elasticOptions.ESQL == "" && elasticOptions.SQL == "" {
 		return fmt.Errorf("--index must be provided")
 	}
+	if elasticOptions.Index == "" && elasticOptions.NeedsTimeField() && elasticOptions.TimeField == "" {
+		return fmt.Errorf("--time-field or --index must be provided to detect the time field of --from and --to")
+	}
+
+	if err := ValidateTransportOptions(elasticOptions.TransportOptions); err != nil {
+		return err
+	}
+
+	return ValidateTLSOptions(elasticOptions.TLSOptions)
+}
+
+// validateCloudID checks that a cloud ID is a deployment name and base64
+// encoded host and cluster id, separated by a colon.
+func validateCloudID(cloudID string) error {
+	_, encoded, found := strings.Cut(cloudID, ":")
+	if found {
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err == nil && strings.Count(string(data), "$") >= 1 {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid --cloud-id, expected the Cloud ID of the deployment, like name:base64")
+}
+
+// ValidateTransportOptions validates the node discovery and retry options.
+func ValidateTransportOptions(transportOptions options.TransportOptions) error {
+	if transportOptions.SniffInterval < 0 {
+		return fmt.Errorf("--sniff-interval must not be negative")
+	}
+
+	if transportOptions.MaxRetries < 0 {
+		return fmt.Errorf("--max-retries must not be negative")
+	}
+
+	for _, status := range transportOptions.RetryOnStatus {
+		if status < 100 || status > 599 {
+			return fmt.Errorf("invalid --retry-on-status %d, expected an HTTP status", status)
+		}
+	}
+
+	if transportOptions.RetryBackoff < 0 {
+		return fmt.Errorf("--retry-backoff must not be negative")
+	}
+
+	if transportOptions.Timeout < 0 {
+		return fmt.Errorf("--timeout must not be negative")
+	}
+
+	return nil
+}
+
+// ValidateTLSOptions validates the TLS options.
+func ValidateTLSOptions(tlsOptions options.TLSOptions) error {
+	if tlsOptions.InsecureSkipVerify && (tlsOptions.CACert != "" || tlsOptions.CAFingerprint != "") {
+		return fmt.Errorf("--insecure-skip-verify cannot be used with --ca-cert or --ca-fingerprint")
+	}
+
+	if (tlsOptions.ClientCert == "") != (tlsOptions.ClientKey == "") {
+		return fmt.Errorf("--client-cert and --client-key must be used together")
+	}
+
+	files := []struct{ flag, path string }{
+		{"--ca-cert", tlsOptions.CACert},
+		{"--client-cert", tlsOptions.ClientCert},
+		{"--client-key", tlsOptions.ClientKey},
+	}
+	for _, file := range files {
+		if file.path == "" {
+			continue
+		}
+		if _, err := os.Stat(file.path); err != nil {
+			return fmt.Errorf("%s file does not exist: %s", file.flag, file.path)
+		}
+	}
+
+	if tlsOptions.CAFingerprint != "" && !sha256Hex.MatchString(tlsOptions.Fingerprint()) {
+		return fmt.Errorf("--ca-fingerprint must be a SHA-256 fingerprint of 64 hex digits")
+	}
 
 	return nil
 }
@@ -1,8 +1,11 @@
 package validation
 
 import (
+	"encoding/base64"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/fa7ad/esq/internal/options"
 	"github.com/stretchr/testify/assert"
@@ -20,10 +23,37 @@ func TestValidateQueryOptions(t *testing.T) {
 		wantErr bool
 	}{
 		{"Valid KQL", options.QueryOptions{KQL: "user:test"}, false},
+		{"Invalid KQL", options.QueryOptions{KQL: "field: (unclosed"}, true},
 		{"Valid DSL", options.QueryOptions{DSL: `{"match_all":{}}`}, false},
 		{"No Query Provided", options.QueryOptions{}, true},
 		{"Multiple Queries (KQL and DSL)", options.QueryOptions{KQL: "user:test", DSL: `{"match_all":{}}`}, true},
 		{"Invalid From Timestamp", options.QueryOptions{KQL: "a", From: "not-a-date"}, true},
+		{"Paging with Limit", options.QueryOptions{KQL: "a", Size: 100, Limit: 1000}, false},
+		{"Negative Limit", options.QueryOptions{KQL: "a", Size: 100, Limit: -1}, true},
+		{"Paging without Page Size", options.QueryOptions{KQL: "a", All: true}, true},
+		{"Aggregations without Query", options.QueryOptions{AggregationOptions: options.AggregationOptions{Terms: []string{"status:20"}}}, false},
+		{"Invalid Terms Size", options.QueryOptions{AggregationOptions: options.AggregationOptions{Terms: []string{"status:many"}}}, true},
+		{"Date Histogram without Interval", options.QueryOptions{AggregationOptions: options.AggregationOptions{DateHistogram: []string{"@timestamp"}}}, true},
+		{"Count without Query", options.QueryOptions{Count: true}, false},
+		{"Count with Paging", options.QueryOptions{KQL: "a", Size: 100, Limit: 10, Count: true}, true},
+		{"Valid Sort", options.QueryOptions{KQL: "a", FieldOptions: options.FieldOptions{Sort: []string{"@timestamp:desc", "host.name"}}}, false},
+		{"Invalid Sort Order", options.QueryOptions{KQL: "a", FieldOptions: options.FieldOptions{Sort: []string{"@timestamp:newest"}}}, true},
+		{"Filters without Query", options.QueryOptions{FilterOptions: options.FilterOptions{Filter: []string{"env=prod"}, Range: []string{"bytes>=1024"}}}, false},
+		{"Filter without Value", options.QueryOptions{KQL: "a", FilterOptions: options.FilterOptions{Filter: []string{"env"}}}, true},
+		{"Range without Operator", options.QueryOptions{KQL: "a", FilterOptions: options.FilterOptions{Range: []string{"bytes=1024"}}}, true},
+		{"ES|QL", options.QueryOptions{ESQL: "FROM logs | LIMIT 10", ESQLParams: []string{"42"}, From: "now-1h"}, false},
+		{"ES|QL with KQL", options.QueryOptions{ESQL: "FROM logs", KQL: "a"}, true},
+		{"ES|QL with Sort", options.QueryOptions{ESQL: "FROM logs", FieldOptions: options.FieldOptions{Sort: []string{"@timestamp"}}}, true},
+		{"ES|QL Params without ES|QL", options.QueryOptions{KQL: "a", ESQLParams: []string{"42"}}, true},
+		{"EQL", options.QueryOptions{EQL: "process where true", EQLOptions: options.EQLOptions{TiebreakerField: "event.sequence"}}, false},
+		{"EQL with ES|QL", options.QueryOptions{EQL: "process where true", ESQL: "FROM logs"}, true},
+		{"EQL with Count", options.QueryOptions{EQL: "process where true", Count: true}, true},
+		{"EQL Fields without EQL", options.QueryOptions{KQL: "a", EQLOptions: options.EQLOptions{TimestampField: "event.created"}}, true},
+		{"SQL", options.QueryOptions{SQL: "SELECT level, COUNT(*) FROM logs GROUP BY level", SQLTranslate: true, From: "now-1h"}, false},
+		{"SQL with EQL", options.QueryOptions{SQL: "SELECT * FROM logs", EQL: "process where true"}, true},
+		{"SQL with Paging", options.QueryOptions{SQL: "SELECT * FROM logs", Size: 100, All: true}, true},
+		{"SQL Translate without SQL", options.QueryOptions{KQL: "a", SQLTranslate: true}, true},
+		{"Aggregations with Paging", options.QueryOptions{KQL: "a", Size: 100, All: true, AggregationOptions: options.AggregationOptions{Stats: []string{"bytes"}}}, true},
 	}
 
 	for _, tc := range testCases {
@@ -49,6 +79,12 @@ func TestValidateOutputOptions(t *testing.T) {
 		wantErr bool
 	}{
 		{"Valid JSON Output", options.OutputOptions{Output: "json"}, false},
+		{"Valid NDJSON Output", options.OutputOptions{Output: "ndjson"}, false},
+		{"Valid CSV Output with Fields", options.OutputOptions{Output: "csv", Fields: []string{"user.name"}}, false},
+		{"Valid Template", options.OutputOptions{Output: "template", Template: "{{.level}} {{.message}}"}, false},
+		{"Invalid Template", options.OutputOptions{Output: "template", Template: "{{.level"}, true},
+		{"Template Format without Template", options.OutputOptions{Output: "template"}, true},
+		{"Template with Other Format", options.OutputOptions{Output: "json", Template: "{{.level}}"}, true},
 		{"Invalid Output Format", options.OutputOptions{Output: "xml"}, true},
 		{"Valid JQ Path", options.OutputOptions{Output: "json", JqPath: ".hits"}, false},
 		{"Invalid JQ Path", options.OutputOptions{Output: "json", JqPath: "{"}, true},
@@ -67,6 +103,56 @@ func TestValidateOutputOptions(t *testing.T) {
 	}
 }
 
+func TestValidateSQLTranslateOutput(t *testing.T) {
+	testCases := []struct {
+		name    string
+		opts    options.OutputOptions
+		wantErr bool
+	}{
+		{"JSON", options.OutputOptions{Output: "json", JqPath: ".query"}, false},
+		{"Table", options.OutputOptions{Output: "table"}, true},
+		{"CSV", options.OutputOptions{Output: "csv"}, true},
+		{"Template", options.OutputOptions{Output: "template", Template: "{{.size}}"}, true},
+		{"Template File", options.OutputOptions{Output: "template", TemplateFile: "search.tmpl"}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateSQLTranslateOutput(tc.opts)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidatePagedOutput(t *testing.T) {
+	testCases := []struct {
+		name    string
+		opts    options.OutputOptions
+		wantErr bool
+	}{
+		{"JSON", options.OutputOptions{Output: "json"}, false},
+		{"Table", options.OutputOptions{Output: "table"}, false},
+		{"CSV with Fields", options.OutputOptions{Output: "csv", Fields: []string{"_id"}}, false},
+		{"CSV", options.OutputOptions{Output: "csv"}, true},
+		{"TSV", options.OutputOptions{Output: "tsv"}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validatePagedOutput(tc.opts)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestValidateAuthOptions(t *testing.T) {
 	testCases := []struct {
 		name    string
@@ -76,6 +162,16 @@ func TestValidateAuthOptions(t *testing.T) {
 		{"API Key Only", options.AuthOptions{APIKey: "key"}, false},
 		{"Username/Password", options.AuthOptions{Username: "user", Password: "pw"}, false},
 		{"API Key with Username", options.AuthOptions{APIKey: "key", Username: "user"}, true},
+		{"Bearer Token Only", options.AuthOptions{BearerToken: "token"}, false},
+		{"Service Token Only", options.AuthOptions{ServiceToken: "token"}, false},
+		{"Bearer Token with Password", options.AuthOptions{BearerToken: "token", Username: "user", Password: "pw"}, true},
+		{"Password from Stdin", options.AuthOptions{Username: "user", PasswordStdin: true}, false},
+		{"Password File without Username", options.AuthOptions{PasswordFile: "pw.txt"}, true},
+		{"Password with Password File", options.AuthOptions{Username: "user", Password: "pw", PasswordFile: "pw.txt"}, true},
+		{"API Key with API Key File", options.AuthOptions{APIKey: "key", APIKeyFile: "key.txt"}, true},
+		{"Credential Command for Password", options.AuthOptions{Username: "user", CredentialCommand: "pass show es"}, false},
+		{"Credential Command with API Key", options.AuthOptions{APIKey: "key", CredentialCommand: "pass show es"}, true},
+		{"Service Token with API Key", options.AuthOptions{ServiceToken: "token", APIKey: "key"}, true},
 	}
 
 	for _, tc := range testCases {
@@ -91,14 +187,32 @@ func TestValidateAuthOptions(t *testing.T) {
 }
 
 func TestValidateElasticOptions(t *testing.T) {
+	cloudID := "my-deployment:" + base64.StdEncoding.EncodeToString([]byte("us-east-1.aws.found.io$abc123$def456"))
+
 	testCases := []struct {
 		name    string
 		opts    options.ElasticOptions
 		wantErr bool
 	}{
-		{"Valid", options.ElasticOptions{Node: "url", Index: "idx"}, false},
+		{"Valid", options.ElasticOptions{Nodes: []string{"http://localhost:9200"}, Index: "idx"}, false},
 		{"No Node", options.ElasticOptions{Index: "idx"}, true},
-		{"No Index", options.ElasticOptions{Node: "url"}, true},
+		{"No Index", options.ElasticOptions{Nodes: []string{"http://localhost:9200"}}, true},
+		{"ES|QL without Index", options.ElasticOptions{Nodes: []string{"http://localhost:9200"}, QueryOptions: options.QueryOptions{ESQL: "FROM logs"}}, false},
+		{"ES|QL Time Range without Index", options.ElasticOptions{Nodes: []string{"http://localhost:9200"}, QueryOptions: options.QueryOptions{ESQL: "FROM logs", From: "now-1h"}}, true},
+		{"SQL without Index", options.ElasticOptions{Nodes: []string{"http://localhost:9200"}, QueryOptions: options.QueryOptions{SQL: "SELECT * FROM logs"}}, false},
+		{"Cloud ID", options.ElasticOptions{CloudID: cloudID, Index: "idx"}, false},
+		{"Cloud ID with Node", options.ElasticOptions{CloudID: cloudID, Nodes: []string{"http://localhost:9200"}, Index: "idx"}, true},
+		{"Invalid Cloud ID", options.ElasticOptions{CloudID: "my-deployment", Index: "idx"}, true},
+		{"Cloud ID with Sniffing", options.ElasticOptions{CloudID: cloudID, Index: "idx", TransportOptions: options.TransportOptions{Sniff: true}}, true},
+		{"Multiple Nodes", options.ElasticOptions{Nodes: []string{"http://es1:9200", "https://es2:9200"}, Index: "idx"}, false},
+		{"Node without Scheme", options.ElasticOptions{Nodes: []string{"localhost:9200"}, Index: "idx"}, true},
+		{"Negative Max Retries", options.ElasticOptions{Nodes: []string{"http://localhost:9200"}, Index: "idx", TransportOptions: options.TransportOptions{MaxRetries: -1}}, true},
+		{"Invalid Retry Status", options.ElasticOptions{Nodes: []string{"http://localhost:9200"}, Index: "idx", TransportOptions: options.TransportOptions{RetryOnStatus: []int{1000}}}, true},
+		{"Valid Fingerprint", options.ElasticOptions{Nodes: []string{"http://localhost:9200"}, Index: "idx", TLSOptions: options.TLSOptions{CAFingerprint: strings.Repeat("AB:", 31) + "AB"}}, false},
+		{"Short Fingerprint", options.ElasticOptions{Nodes: []string{"http://localhost:9200"}, Index: "idx", TLSOptions: options.TLSOptions{CAFingerprint: "abcd"}}, true},
+		{"Insecure with Fingerprint", options.ElasticOptions{Nodes: []string{"http://localhost:9200"}, Index: "idx", TLSOptions: options.TLSOptions{CAFingerprint: strings.Repeat("ab", 32), InsecureSkipVerify: true}}, true},
+		{"Client Certificate without Key", options.ElasticOptions{Nodes: []string{"http://localhost:9200"}, Index: "idx", TLSOptions: options.TLSOptions{ClientCert: "cert.pem"}}, true},
+		{"Missing CA Certificate", options.ElasticOptions{Nodes: []string{"http://localhost:9200"}, Index: "idx", TLSOptions: options.TLSOptions{CACert: "/does/not/exist.pem"}}, true},
 	}
 
 	for _, tc := range testCases {
@@ -112,3 +226,57 @@ func TestValidateElasticOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateExportOptions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		opts    options.ExportOptions
+		output  options.OutputOptions
+		wantErr bool
+	}{
+		{"Valid", options.ExportOptions{Slices: 4, Workers: 2}, options.OutputOptions{}, false},
+		{"No Slices", options.ExportOptions{Slices: 0, Workers: 2}, options.OutputOptions{}, true},
+		{"No Workers", options.ExportOptions{Slices: 4, Workers: 0}, options.OutputOptions{}, true},
+		{"Per Slice without Output File", options.ExportOptions{Slices: 4, Workers: 2, PerSlice: true}, options.OutputOptions{}, true},
+		{"Per Slice with Output File", options.ExportOptions{Slices: 4, Workers: 2, PerSlice: true}, options.OutputOptions{OutputFile: "does-not-exist.ndjson"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateExportOptions(tc.opts, tc.output)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateTailOptions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		opts    options.TailOptions
+		output  options.OutputOptions
+		wantErr bool
+	}{
+		{"Valid", options.TailOptions{Interval: time.Second}, options.OutputOptions{Output: "json"}, false},
+		{"No Interval", options.TailOptions{}, options.OutputOptions{Output: "json"}, true},
+		{"CSV", options.TailOptions{Interval: time.Second}, options.OutputOptions{Output: "csv"}, false},
+		{"Follow CSV with Fields", options.TailOptions{Follow: true, Interval: time.Second}, options.OutputOptions{Output: "csv", Fields: []string{"message"}}, false},
+		{"Follow CSV", options.TailOptions{Follow: true, Interval: time.Second}, options.OutputOptions{Output: "csv"}, true},
+		{"Follow TSV", options.TailOptions{Follow: true, Interval: time.Second}, options.OutputOptions{Output: "tsv"}, true},
+		{"Follow Table", options.TailOptions{Follow: true, Interval: time.Second}, options.OutputOptions{Output: "table"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateTailOptions(tc.opts, tc.output)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}